@@ -0,0 +1,88 @@
+// Helpers for running apps on a virtual clock
+package apps
+
+import (
+	"europi/clock"
+	"europi/controls"
+	"europi/display"
+	"testing"
+	"time"
+)
+
+// appRun is an app running in the background on a virtual clock. Time only
+// moves while the app is asleep, so every step is seen by the app.
+type appRun struct {
+	t    *testing.T
+	hw   *controls.Controls
+	oled *display.MockOledDevice
+	clk  *clock.Virtual
+	done chan struct{}
+}
+
+func startApp(t *testing.T, app interface{ Run(*controls.Controls) }, setup func(hw *controls.Controls)) *appRun {
+	t.Helper()
+	oled := display.NewMockOledDevice(3, 16)
+	clk := clock.NewVirtual(time.Time{})
+	r := &appRun{t: t, oled: oled, clk: clk, done: make(chan struct{})}
+	r.hw = controls.SetupMockEuroPiWithClock(oled, clk)
+	if setup != nil {
+		setup(r.hw)
+	}
+	go func() {
+		defer close(r.done)
+		app.Run(r.hw)
+	}()
+	return r
+}
+
+// advance moves the clock on by d in 10ms steps, waiting for the app to go
+// back to sleep before each step. Stops early if the app exits.
+func (r *appRun) advance(d time.Duration) {
+	r.t.Helper()
+	const step = 10 * time.Millisecond
+	for elapsed := time.Duration(0); elapsed < d; elapsed += step {
+		if !r.waitForSleep() {
+			return
+		}
+		r.clk.Advance(step)
+	}
+}
+
+func (r *appRun) waitForSleep() bool {
+	r.t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for r.clk.Pending() == 0 {
+		select {
+		case <-r.done:
+			return false
+		default:
+		}
+		if time.Now().After(deadline) {
+			r.t.Fatal("App neither slept nor exited")
+		}
+		time.Sleep(100 * time.Microsecond)
+	}
+	return true
+}
+
+// press holds a button down for hold, then lets go and waits 100ms.
+func (r *appRun) press(b controls.IButton, hold time.Duration) {
+	r.t.Helper()
+	r.waitForSleep()
+	b.(*controls.MockButton).SetPressed(true)
+	r.advance(hold)
+	b.(*controls.MockButton).SetPressed(false)
+	r.advance(100 * time.Millisecond)
+}
+
+// exited reports whether the app has returned, giving it d of virtual time to do so.
+func (r *appRun) exited(d time.Duration) bool {
+	r.t.Helper()
+	r.advance(d)
+	select {
+	case <-r.done:
+		return true
+	case <-time.After(time.Second):
+		return false
+	}
+}
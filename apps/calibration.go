@@ -0,0 +1,176 @@
+// Calibration app: measures this unit's analogue input and CV outputs
+package apps
+
+import (
+	"europi/buttons"
+	"europi/controls"
+	"fmt"
+	"time"
+)
+
+/*
+Calibration
+
+Guides you through calibrating the analogue input (AIN) and the six CV outputs.
+
+ 1. AIN: unplug AIN to measure 0V, then apply a known voltage (chosen with K2)
+    from a trusted source to measure the input scale.
+ 2. CV1..CV6: patch each output into AIN. The app steps the output through its
+    PWM range and measures the voltage with the freshly calibrated AIN,
+    building a volts to duty table for SetVolts.
+
+B2 confirms a step, B1 skips it, holding both buttons exits without saving.
+The result is stored in Settings and picked up by AIN and the CVs at boot.
+*/
+type Calibration struct{}
+
+func (Calibration) Name() string { return "Calibrate" }
+
+const (
+	calSamples = 64                    // ADC readings averaged per measurement
+	calSettle  = 50 * time.Millisecond // time for a CV output to settle before measuring
+	calSteps   = 10                    // duty steps measured per CV output
+)
+
+func (Calibration) Run(hw *controls.Controls) {
+	btnMgr := buttons.NewWithClock(hw.B1, hw.B2, hw.Clock)
+	defer btnMgr.Close()
+	cal := controls.LoadProfileCalibration(hw.Settings, hw.Profile)
+
+	// --- Analogue input ---
+	switch calPrompt(hw, btnMgr, "AIN calibration", "Unplug AIN", "B2:ok B1:skip") {
+	case buttons.None:
+		return
+	case buttons.B2Press:
+		rawAtZero := calReadRaw(hw)
+		refVolts, ev := calChooseVolts(hw, btnMgr)
+		if ev == buttons.None {
+			return
+		}
+		if ev == buttons.B2Press {
+			rawAtRef := calReadRaw(hw)
+			if rawAtRef-rawAtZero < 1000 {
+				calMessage(hw, "AIN reading bad", "keeping old cal")
+			} else {
+				cal.AIN = controls.AINCalibrationFromReadings(rawAtZero, rawAtRef, refVolts)
+			}
+		}
+	}
+
+	// --- CV outputs ---
+	cvs := []controls.ICV{hw.CV1, hw.CV2, hw.CV3, hw.CV4, hw.CV5, hw.CV6}
+	for i, cv := range cvs {
+		ev := calPrompt(hw, btnMgr, fmt.Sprintf("CV%d calibration", i+1), fmt.Sprintf("Patch CV%d->AIN", i+1), "B2:ok B1:skip")
+		if ev == buttons.None {
+			return
+		}
+		if ev != buttons.B2Press {
+			continue
+		}
+		hw.Display.ClearBuffer()
+		hw.Display.WriteLine(0, fmt.Sprintf("Measuring CV%d", i+1))
+		hw.Display.Display()
+		points := calMeasureCV(hw, cv, cal.AIN)
+		if len(points) < 2 {
+			calMessage(hw, fmt.Sprintf("CV%d not patched?", i+1), "keeping old cal")
+			continue
+		}
+		cal.CV[i] = controls.NewCVCalibration(points)
+		calMessage(hw, fmt.Sprintf("CV%d ok", i+1), fmt.Sprintf("%.2f-%.2fV", points[0].Volts, points[len(points)-1].Volts))
+	}
+
+	// --- Save ---
+	if hw.Settings == nil {
+		// No store to save to, e.g. a plain mock setup, so only use it until power off
+		hw.ApplyCalibration(cal)
+		calMessage(hw, "Calibration", "not saved")
+		return
+	}
+	controls.SaveCalibration(hw.Settings, cal)
+	if err := hw.Settings.Save(); err != nil {
+		calMessage(hw, "Save failed", err.Error())
+		return
+	}
+	hw.ApplyCalibration(cal)
+	calMessage(hw, "Calibration", "saved")
+}
+
+// calPrompt shows lines and waits for B1 or B2. Returns buttons.None if both
+// buttons are held to exit.
+func calPrompt(hw *controls.Controls, btnMgr *buttons.ButtonManager, lines ...string) buttons.Event {
+	hw.Display.ClearBuffer()
+	for i, line := range lines {
+		hw.Display.WriteLine(i, line)
+	}
+	hw.Display.Display()
+	for {
+		switch ev := btnMgr.Update(); ev {
+		case buttons.B1Press, buttons.B2Press:
+			return ev
+		}
+		if btnMgr.BothHeld() {
+			return buttons.None
+		}
+		hw.Clock.Sleep(10 * time.Millisecond)
+	}
+}
+
+// calChooseVolts lets K2 pick the reference voltage (1..10V) applied to AIN.
+func calChooseVolts(hw *controls.Controls, btnMgr *buttons.ButtonManager) (float64, buttons.Event) {
+	lastVolts := -1
+	for {
+		volts := 1 + hw.K2.Value()*9/100
+		if volts != lastVolts {
+			hw.Display.ClearBuffer()
+			hw.Display.WriteLine(0, fmt.Sprintf("Apply %dV to AIN", volts))
+			hw.Display.WriteLine(1, "K2:volts")
+			hw.Display.WriteLine(2, "B2:ok B1:skip")
+			hw.Display.Display()
+			lastVolts = volts
+		}
+		switch ev := btnMgr.Update(); ev {
+		case buttons.B1Press, buttons.B2Press:
+			return float64(volts), ev
+		}
+		if btnMgr.BothHeld() {
+			return 0, buttons.None
+		}
+		hw.Clock.Sleep(10 * time.Millisecond)
+	}
+}
+
+// calMeasureCV steps cv through its duty range and returns the measured points,
+// dropping points once the output (or AIN) stops rising.
+func calMeasureCV(hw *controls.Controls, cv controls.ICV, ain controls.AINCalibration) []controls.CVCalPoint {
+	var points []controls.CVCalPoint
+	for step := 0; step <= calSteps; step++ {
+		duty := uint32(uint64(controls.MaxDuty) * uint64(step) / calSteps)
+		cv.Set(duty)
+		hw.Clock.Sleep(calSettle)
+		volts := ain.Volts(calReadRaw(hw))
+		if len(points) > 0 && volts <= points[len(points)-1].Volts+0.01 {
+			continue
+		}
+		points = append(points, controls.CVCalPoint{Volts: volts, Duty: duty})
+	}
+	cv.Off()
+	return points
+}
+
+// calReadRaw averages several raw AIN readings.
+func calReadRaw(hw *controls.Controls) int {
+	sum := 0
+	for i := 0; i < calSamples; i++ {
+		sum += hw.AIN.Raw()
+		hw.Clock.Sleep(time.Millisecond)
+	}
+	return sum / calSamples
+}
+
+func calMessage(hw *controls.Controls, line1, line2 string) {
+	hw.Display.ClearBuffer()
+	hw.Display.WriteLine(0, line1)
+	hw.Display.WriteLine(1, line2)
+	hw.Display.Display()
+	hw.Clock.Sleep(1 * time.Second)
+}
@@ -0,0 +1,33 @@
+package apps
+
+import (
+	"europi/controls"
+	"testing"
+	"time"
+)
+
+func TestCalibrationWithoutSettings(t *testing.T) {
+	r := startApp(t, Calibration{}, func(hw *controls.Controls) { hw.Settings = nil })
+	for i := 0; i < 7; i++ { // skip AIN and the six CVs
+		r.press(r.hw.B1, 100*time.Millisecond)
+	}
+	if !r.exited(2 * time.Second) {
+		t.Fatal("Expected Calibration to finish after every step was skipped")
+	}
+	if got := r.oled.LinesRaw[1]; got != "not saved" {
+		t.Errorf("Expected to be told the calibration wasn't saved, got %q", got)
+	}
+}
+
+func TestCalibrationTakesSlowPresses(t *testing.T) {
+	r := startApp(t, Calibration{}, nil)
+	for i := 0; i < 7; i++ { // long enough to also be a long press
+		r.press(r.hw.B1, 700*time.Millisecond)
+	}
+	if !r.exited(2 * time.Second) {
+		t.Fatal("Expected slow presses to skip every step")
+	}
+	if got := r.oled.LinesRaw[1]; got != "saved" {
+		t.Errorf("Expected the calibration to be saved, got %q", got)
+	}
+}
@@ -3,6 +3,9 @@ package apps
 import (
 	"europi/buttons"
 	"europi/controls"
+	"europi/display"
+	"europi/firmware"
+	"europi/util"
 	"fmt"
 	"math"
 	"runtime"
@@ -11,18 +14,45 @@ import (
 )
 
 // MultipliersEditor provides a UI and logic for editing pulse multipliers/divisors.
+// K2 edits whichever CV K1 selects, with soft takeover so that switching CVs
+// doesn't make the newly selected CV jump to wherever K2 happens to be.
 type MultipliersEditor struct {
-	pulses    []*PulseOutput
-	selected  int // 0..4 (CV1, CV2, CV4, CV5, CV6)
-	knob2Last int
+	pulses   []*PulseOutput
+	selected int // 0..4 (CV1, CV2, CV4, CV5, CV6)
+	pickup   *util.Pickup
 }
 
 func NewMultipliersEditor(pulses []*PulseOutput, knob2 int) *MultipliersEditor {
-	return &MultipliersEditor{
-		pulses:    pulses,
-		selected:  0,
-		knob2Last: knob2,
+	e := &MultipliersEditor{
+		pulses:   pulses,
+		selected: 0,
+		pickup:   util.NewPickup(util.PickupCatch),
 	}
+	e.Reset(knob2)
+	return e
+}
+
+// Reset hands K2 (currently at knob2) over to the selected CV's multiplier.
+// Called when the selection changes and whenever the editor is opened, since
+// K2 may have been moved for tempo while the editor was closed.
+func (e *MultipliersEditor) Reset(knob2 int) {
+	e.pickup.Reset(multKnobPosition(e.pulses[e.selectedPulseIdx()]), knob2)
+}
+
+// selectedPulseIdx returns the pulse index being edited (CV3 is skipped).
+func (e *MultipliersEditor) selectedPulseIdx() int {
+	if e.selected < 2 {
+		return e.selected // CV1, CV2
+	}
+	return e.selected + 1 // CV4, CV5, CV6
+}
+
+// multKnobPosition returns the K2 position (0..100) that selects p's current multiplier/divisor.
+func multKnobPosition(p *PulseOutput) int {
+	if p.Mult >= 1.0 {
+		return int(math.Round((p.Mult - 1.0) * 20)) // inverse of 1.0 + k2/20
+	}
+	return (p.Divisor - 2) * 20 // inverse of 2 + k2/20
 }
 
 func (e *MultipliersEditor) HandleControls(state *PulseState) {
@@ -31,6 +61,7 @@ func (e *MultipliersEditor) HandleControls(state *PulseState) {
 		state.editingMultipliers = !state.editingMultipliers
 		state.updateUI = true
 		if !state.editingMultipliers {
+			state.saveSettings()
 			return // If exiting editor, no further processing needed
 		}
 	}
@@ -52,28 +83,21 @@ func (e *MultipliersEditor) HandleControls(state *PulseState) {
 	}
 	if newSelected != e.selected {
 		e.selected = newSelected
+		e.Reset(k2)
 		state.updateUI = true
 	}
-	// Get the actual pulse index for editing (skip CV3)
-	var pulseIdx int
-	if e.selected < 2 {
-		pulseIdx = e.selected // CV1, CV2
-	} else {
-		pulseIdx = e.selected + 1 // CV4, CV5, CV6
-	}
-	if k2 != e.knob2Last {
-		p := e.pulses[pulseIdx]
+	if k2v, changed := e.pickup.Update(k2); changed {
+		p := e.pulses[e.selectedPulseIdx()]
 		// Only allow editing multiplier/divisor for this pulse
 		if p.Mult >= 1.0 {
 			// Range: 1x to 8x
-			p.Mult = 1.0 + float64(k2)/20.0 // K2: 0-100 -> 1.0-6.0
+			p.Mult = 1.0 + float64(k2v)/20.0 // K2: 0-100 -> 1.0-6.0
 		} else {
 			// Range: 1/2 to 1/8
-			div := 2 + k2/20 // K2: 0-100 -> 2-7
+			div := 2 + k2v/20 // K2: 0-100 -> 2-7
 			p.Mult = 1.0 / float64(div)
 			p.Divisor = int(math.Round(1.0 / p.Mult))
 		}
-		e.knob2Last = k2
 		state.updateUI = true
 	}
 	if state.btnMgr.BothHeld() {
@@ -84,13 +108,21 @@ func (e *MultipliersEditor) HandleControls(state *PulseState) {
 
 func (e *MultipliersEditor) DrawScreen(hw *controls.Controls) {
 	hw.Display.ClearBuffer()
-	// First line: CV1 CV2 CV3
-	line1 := ""
-	for i := 0; i < 3; i++ {
-		var val string
-		if i == 2 {
-			val = "1:1"
-		} else {
+	// First line: CV1 CV2 CV3, second line: CV4 CV5 CV6
+	e.drawLine(hw, 0, 0)
+	e.drawLine(hw, 1, 3)
+	hw.Display.WriteLine(2, "K1:sel K2:edit")
+	hw.Display.Display()
+}
+
+// drawLine writes the three CVs from pulse index first, inverting the one
+// being edited.
+func (e *MultipliersEditor) drawLine(hw *controls.Controls, lineNum, first int) {
+	line := ""
+	var spans []display.Span
+	for i := first; i < first+3; i++ {
+		val := "1:1" // CV3 follows the clock and can't be edited
+		if i != 2 {
 			p := e.pulses[i]
 			if p.Mult < 1.0 {
 				val = fmt.Sprintf("1/%d", p.Divisor)
@@ -98,39 +130,13 @@ func (e *MultipliersEditor) DrawScreen(hw *controls.Controls) {
 				val = fmt.Sprintf("%.1fx", p.Mult)
 			}
 		}
-		sel := " "
-		// Cursor: only for editable CVs
-		if (e.selected == i) && (i != 2) {
-			sel = ">"
+		field := " " + val + " "
+		if i == e.selectedPulseIdx() {
+			spans = append(spans, display.Invert(len(line), len(line)+len(field)))
 		}
-		line1 += fmt.Sprintf("%s%s ", sel, val)
+		line += field
 	}
-	hw.Display.WriteLine(0, line1)
-
-	// Second line: CV4 CV5 CV6
-	line2 := ""
-	for i := 3; i < 6; i++ {
-		p := e.pulses[i]
-		var val string
-		if p.Mult < 1.0 {
-			val = fmt.Sprintf("1/%d", p.Divisor)
-		} else {
-			val = fmt.Sprintf("%.1fx", p.Mult)
-		}
-		sel := " "
-		// Cursor: only for editable CVs
-		if e.selected == (i-1) && i != 3 {
-			sel = ">"
-		}
-		if e.selected == 2 && i == 3 {
-			sel = ">"
-		}
-		line2 += fmt.Sprintf("%s%s ", sel, val)
-	}
-	hw.Display.WriteLine(1, line2)
-
-	hw.Display.WriteLine(2, "K1:sel K2:edit")
-	hw.Display.Display()
+	hw.Display.WriteLineSpans(lineNum, line, spans...)
 }
 
 // MultiPulseSync is a clock-synchronized pulse generator.
@@ -145,11 +151,9 @@ func (MultiPulseSync) Name() string { return "Pulse Sync" }
 // PulseOutput represents a single CV output channel.
 type PulseOutput struct {
 	CV      controls.ICV
-	Mult    float64   // Clock multiplier. >1.0 for faster, <1.0 for slower.
-	Divisor int       // For divisions (Mult < 1.0), stores the division factor (e.g., 4 for 1/4 speed).
-	Phase   float64   // For multiplications (Mult > 1.0), current phase from 0.0 to 1.0.
-	IsHigh  bool      // True if the CV output is currently high.
-	NextOff time.Time // The time at which the current pulse should end.
+	Mult    float64 // Clock multiplier. >1.0 for faster, <1.0 for slower.
+	Divisor int     // For divisions (Mult < 1.0), stores the division factor (e.g., 4 for 1/4 speed).
+	Phase   float64 // For multiplications (Mult > 1.0), current phase from 0.0 to 1.0.
 }
 
 // PulseState holds the complete state of the application.
@@ -165,9 +169,9 @@ type PulseState struct {
 	lastDinTime  time.Time     // Time of the last DIN signal, for period calculation.
 	lastTickTime time.Time     // Time of the last processing loop tick, for calculating delta time.
 	dinHz        float64
-	dinCounter   int       // A counter that increments on each DIN pulse or internal tick, used for divisions.
-	freeRunPhase float64   // Phase accumulator for the internal clock in free mode.
-	edgeEvents   chan bool // Channel to receive rising edge events from the DIN ISR.
+	dinCounter   int                       // A counter that increments on each DIN pulse or internal tick, used for divisions.
+	freeRunPhase float64                   // Phase accumulator for the internal clock in free mode.
+	din          *firmware.DINSubscription // DIN edges, timestamped in the ISR.
 
 	knob1, knob2 int
 	updateUI     bool // Flag to trigger a screen redraw.
@@ -191,7 +195,7 @@ func resetAndFireMultipliers(state *PulseState, now time.Time, debugLabel string
 			oldPhase := p.Phase
 			p.Phase = 0.0
 			if state.cvEnabled {
-				firePulse(p, now, state.pulseWidth)
+				state.firePulse(p, now)
 			}
 			if state.debug {
 				fmt.Printf("[%s] CV%d phase reset from %.4f to 0.0000 and pulse fired\n", debugLabel, i+1, oldPhase)
@@ -206,14 +210,14 @@ func resetAndFireMultipliers(state *PulseState, now time.Time, debugLabel string
 func (MultiPulseSync) Run(hw *controls.Controls) {
 	state := &PulseState{
 		hw:           hw,
-		btnMgr:       buttons.New(hw.B1, hw.B2),
+		btnMgr:       buttons.NewWithClock(hw.B1, hw.B2, hw.Clock),
 		running:      true,
 		syncToDIN:    true,
 		cvEnabled:    true,
 		dinPeriod:    500 * time.Millisecond, // Default to 120 BPM.
 		pulseWidth:   20 * time.Millisecond,  // A reasonable default pulse width.
-		edgeEvents:   make(chan bool, 16),    // Buffered channel for ISR events.
-		updateUI:     true,                   // Initial UI draw.
+		din:          firmware.SubscribeDIN(hw, 16),
+		updateUI:     true, // Initial UI draw.
 		dinCounter:   0,
 		freeRunPhase: 0.0,
 		debug:        false, // Set to true for debug output.
@@ -228,6 +232,7 @@ func (MultiPulseSync) Run(hw *controls.Controls) {
 		{CV: hw.CV5, Mult: 3.0},  // 3x speed
 		{CV: hw.CV6, Mult: 4.0},  // 4x speed
 	}
+	state.loadSettings()
 	for _, p := range state.pulses {
 		if p.Mult > 0 && p.Mult < 1.0 {
 			p.Divisor = int(math.Round(1.0 / p.Mult))
@@ -236,26 +241,16 @@ func (MultiPulseSync) Run(hw *controls.Controls) {
 
 	state.multipliersEditor = NewMultipliersEditor(state.pulses, state.knob2)
 
-	// --- Setup ISR for DIN sync ---
-	hw.DIN.SetEdgeHandlers(
-		func() { // Rising edge handler
-			if state.syncToDIN {
-				select {
-				case state.edgeEvents <- true:
-				default: // Channel is full, event is dropped. This is OK.
-				}
-			}
-		},
-		func() {}, // Falling edge is ignored.
-	)
-
 	var wg sync.WaitGroup
 	defer func() {
-		hw.DIN.UnsetInterrupt()
+		state.din.Close()
+		state.btnMgr.Close()
 		wg.Wait()
+		hw.Scheduler.CancelAll()
 		for _, p := range state.pulses {
 			p.CV.Off()
 		}
+		state.saveSettings()
 	}()
 
 	// --- UI Update Loop ---
@@ -271,14 +266,14 @@ func (MultiPulseSync) Run(hw *controls.Controls) {
 				}
 				state.updateUI = false
 			}
-			time.Sleep(100 * time.Millisecond)
+			state.hw.Clock.Sleep(100 * time.Millisecond)
 		}
 	}()
 
 	// --- Main Application Loop ---
-	state.lastTickTime = time.Now()
+	state.lastTickTime = hw.Clock.Now()
 	for state.running {
-		now := time.Now()
+		now := hw.Clock.Now()
 		deltaTime := now.Sub(state.lastTickTime)
 		state.lastTickTime = now
 
@@ -300,9 +295,14 @@ func (MultiPulseSync) Run(hw *controls.Controls) {
 
 		// 3. Check for triggers from DIN or internal free-run clock
 		dinTrigger := false
+		var dinAt time.Time
 		select {
-		case <-state.edgeEvents:
-			dinTrigger = true
+		case ev := <-state.din.Events():
+			// Falling edges are ignored
+			if ev.Rising && state.syncToDIN {
+				dinTrigger = true
+				dinAt = ev.At
+			}
 		default:
 		}
 
@@ -320,9 +320,9 @@ func (MultiPulseSync) Run(hw *controls.Controls) {
 		var justSynced map[*PulseOutput]bool
 		if dinTrigger {
 			// --- A real DIN event occurred ---
-			now := time.Now()
+			now := hw.Clock.Now()
 			if !state.lastDinTime.IsZero() {
-				newPeriod := now.Sub(state.lastDinTime)
+				newPeriod := dinAt.Sub(state.lastDinTime)
 				if state.justSwitchedToDIN {
 					state.dinPeriod = newPeriod
 					state.justSwitchedToDIN = false
@@ -340,7 +340,7 @@ func (MultiPulseSync) Run(hw *controls.Controls) {
 					}
 				}
 			}
-			state.lastDinTime = now
+			state.lastDinTime = dinAt
 			state.dinCounter++
 			state.freeRunPhase = 0.0 // Reset free-run phase to sync it.
 
@@ -353,7 +353,7 @@ func (MultiPulseSync) Run(hw *controls.Controls) {
 
 		} else if freeTrigger {
 			// --- A virtual free-run tick occurred ---
-			now := time.Now()
+			now := hw.Clock.Now()
 			state.dinCounter++
 			state.processTick(now) // Fire divisions and 1:1 clock
 
@@ -369,12 +369,6 @@ func (MultiPulseSync) Run(hw *controls.Controls) {
 			deltaSeconds := deltaTime.Seconds()
 
 			for _, pulse := range state.pulses {
-				// Turn off any pulse that has finished its duration.
-				if pulse.IsHigh && now.After(pulse.NextOff) {
-					pulse.CV.Off()
-					pulse.IsHigh = false
-				}
-
 				// Phase accumulation logic ONLY applies to multipliers.
 				if pulse.Mult > 1.0 {
 					// Suppress phase accumulation and [FIRE] debug if just synced this tick
@@ -387,7 +381,7 @@ func (MultiPulseSync) Run(hw *controls.Controls) {
 						if state.debug {
 							fmt.Printf("[FIRE] CV%d t=%v phase=%.4f\n", findCVIndex(state.pulses, pulse)+1, now.Format("15:04:05.000"), pulse.Phase)
 						}
-						firePulse(pulse, now, state.pulseWidth)
+						state.firePulse(pulse, now)
 						pulse.Phase -= 1.0 // Wrap phase
 					}
 				}
@@ -408,21 +402,45 @@ func (s *PulseState) processTick(now time.Time) {
 		// Divisions are triggered by the DIN counter.
 		if pulse.Mult < 1.0 {
 			if pulse.Divisor > 0 && (s.dinCounter-1)%pulse.Divisor == 0 {
-				firePulse(pulse, now, s.pulseWidth)
+				s.firePulse(pulse, now)
 			}
 		} else if pulse.Mult == 1.0 {
 			// The 1x pulse fires on every tick.
-			firePulse(pulse, now, s.pulseWidth)
+			s.firePulse(pulse, now)
 		}
 	}
 }
 
-// firePulse is a helper to turn a CV on and set its off time.
-func firePulse(p *PulseOutput, now time.Time, width time.Duration) {
-	if !p.IsHigh {
-		p.CV.On()
-		p.IsHigh = true
-		p.NextOff = now.Add(width)
+// firePulse hands a pulse to the output scheduler, which turns it off again on time.
+func (s *PulseState) firePulse(p *PulseOutput, now time.Time) {
+	s.hw.Scheduler.ScheduleGate(p.CV, now, s.pulseWidth)
+}
+
+// loadSettings restores the multipliers and sync mode saved by a previous run.
+func (s *PulseState) loadSettings() {
+	if s.hw.Settings == nil {
+		return
+	}
+	s.syncToDIN = s.hw.Settings.GetBool("pulsesync.sync", s.syncToDIN)
+	for i, p := range s.pulses {
+		if mult := s.hw.Settings.GetFloat(fmt.Sprintf("pulsesync.mult%d", i+1), p.Mult); mult > 0 {
+			p.Mult = mult
+		}
+	}
+}
+
+// saveSettings persists the multipliers and sync mode. The store only writes
+// to flash when something actually changed.
+func (s *PulseState) saveSettings() {
+	if s.hw.Settings == nil {
+		return
+	}
+	s.hw.Settings.SetBool("pulsesync.sync", s.syncToDIN)
+	for i, p := range s.pulses {
+		s.hw.Settings.SetFloat(fmt.Sprintf("pulsesync.mult%d", i+1), p.Mult)
+	}
+	if err := s.hw.Settings.Save(); err != nil {
+		println("Pulse Sync: saving settings failed:", err.Error())
 	}
 }
 
@@ -434,16 +452,14 @@ func (s *PulseState) handleControls() {
 		s.editingMultipliers = !s.editingMultipliers
 		s.updateUI = true
 		if s.editingMultipliers {
+			s.multipliersEditor.Reset(s.hw.K2.Value())
 			return // If entering editor, no further processing needed
 		}
 		// Old CV toggle logic
 		// s.cvEnabled = !s.cvEnabled
 		// if !s.cvEnabled {
 		// 	for _, p := range s.pulses {
-		// 		if p.IsHigh {
-		// 			p.CV.Off()
-		// 			p.IsHigh = false
-		// 		}
+		// 		s.hw.Scheduler.Cancel(p.CV)
 		// 		p.Phase = 0.0
 		// 	}
 		// }
@@ -459,6 +475,7 @@ func (s *PulseState) handleControls() {
 			s.justSwitchedToDIN = true
 			s.lastDinTime = time.Time{} // Reset to zero so first DIN tick is ignored
 		}
+		s.saveSettings()
 		s.updateUI = true
 	}
 
@@ -491,26 +508,8 @@ func (s *PulseState) drawScreen() {
 
 	// Compose the full line string for all CVs
 	fullLine := fmt.Sprintf("K1:help CV1:%s CV2:%s CV3:%s CV4:%s CV5:%s CV6:%s Press B1 to edit CV multipliers, Press B2 to toggle clock sync (DIN/Free running)", formatMult(s.pulses[0]), formatMult(s.pulses[1]), formatMult(s.pulses[2]), formatMult(s.pulses[3]), formatMult(s.pulses[4]), formatMult(s.pulses[5]))
-	// Physical display width is 16 chars
-	displayWidth := 16
-	// Use K1 to set scroll offset (0..100 maps to 0..maxOffset)
-	maxOffset := 0
-	if len(fullLine) > displayWidth {
-		maxOffset = len(fullLine) - displayWidth
-	}
-	scrollOffset := 0
-	if maxOffset > 0 {
-		scrollOffset = (s.knob1 * maxOffset) / 100
-		if scrollOffset > maxOffset {
-			scrollOffset = maxOffset
-		}
-	}
-	// Render the visible window
-	visible := fullLine
-	if len(fullLine) > displayWidth {
-		visible = fullLine[scrollOffset : scrollOffset+displayWidth]
-	}
-	s.hw.Display.WriteLine(2, visible)
+	// K1 scrolls through it, 16 chars at a time in 8x8 font mode or 21 with TinyFont
+	s.hw.Display.WriteLine(2, display.KnobScroll(fullLine, s.hw.Display.CharsPerLine(), s.knob1))
 
 	if s.syncToDIN {
 		s.hw.Display.WriteLine(1, fmt.Sprintf("%.1fHz %dms", s.dinHz, s.dinPeriod.Milliseconds()))
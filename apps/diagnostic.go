@@ -50,22 +50,8 @@ func (c Diagnostic) Run(hw *controls.Controls) {
 		cvLoopCount--
 		if cvLoopCount <= 0 {
 			cvLoopCount = CV_STEP
-			for cv := 1; cv <= 6; cv++ {
-				duty := uint32(rand.Intn(10000)) // Replace 10000 with io.CV1.MaxDuty if needed
-				switch cv {
-				case 1:
-					hw.CV1.Set(duty)
-				case 2:
-					hw.CV2.Set(duty)
-				case 3:
-					hw.CV3.Set(duty)
-				case 4:
-					hw.CV4.Set(duty)
-				case 5:
-					hw.CV5.Set(duty)
-				case 6:
-					hw.CV6.Set(duty)
-				}
+			for _, cv := range hw.CVs() {
+				cv.Set(uint32(rand.Intn(int(controls.MaxDuty) + 1)))
 			}
 		}
 
@@ -82,6 +68,6 @@ func (c Diagnostic) Run(hw *controls.Controls) {
 		hw.Display.WriteLine(3, "DIN:"+dinDisp+" AIN:"+ainDisp)
 		hw.Display.Display()
 
-		time.Sleep(10 * time.Millisecond)
+		hw.Clock.Sleep(10 * time.Millisecond)
 	}
 }
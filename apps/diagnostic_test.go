@@ -0,0 +1,31 @@
+package apps
+
+import (
+	"europi/controls"
+	"testing"
+	"time"
+)
+
+// The Diagnostic Tester checks raw output levels, so its CVs must jump
+// straight to each value rather than glide
+func TestDiagnosticWritesRawLevels(t *testing.T) {
+	r := startApp(t, Diagnostic{}, nil)
+	r.advance(time.Second)
+	for i, cv := range r.hw.CVs() {
+		history := cv.(*controls.MockCV).History()
+		if len(history) == 0 {
+			t.Fatalf("Expected CV%d to be set", i+1)
+		}
+		for j := 1; j < len(history); j++ {
+			if gap := history[j].At.Sub(history[j-1].At); gap < 90*time.Millisecond {
+				t.Fatalf("Expected CV%d to change once per step, got changes %v apart", i+1, gap)
+			}
+		}
+	}
+
+	r.hw.B1.(*controls.MockButton).SetPressed(true)
+	r.hw.B2.(*controls.MockButton).SetPressed(true)
+	if !r.exited(3 * time.Second) {
+		t.Fatal("Expected holding both buttons to exit")
+	}
+}
@@ -36,11 +36,11 @@ func (c FontDisplay) Run(hw *controls.Controls) {
 		if firmware.ShouldExit(hw) {
 			break
 		}
-		time.Sleep(20 * time.Millisecond)
+		hw.Clock.Sleep(20 * time.Millisecond)
 	}
 	logutil.Println("Exiting Font application.")
 	hw.Display.ClearBuffer()
 	hw.Display.Display()
 	logutil.Println("Display cleared. Goodbye!")
-	time.Sleep(1 * time.Second)
+	hw.Clock.Sleep(1 * time.Second)
 }
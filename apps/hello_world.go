@@ -27,5 +27,5 @@ func (c HelloWorld) Run(hw *controls.Controls) {
 	hw.Display.ClearDisplay()
 	hw.Display.Display()
 	logutil.Println("Display cleared. Goodbye!")
-	time.Sleep(1 * time.Second)
+	hw.Clock.Sleep(1 * time.Second)
 }
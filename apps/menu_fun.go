@@ -53,7 +53,7 @@ func (MenuFun) Run(hw *controls.Controls) {
 			hw.Display.ClearDisplay()
 			hw.Display.Display()
 			logutil.Println("Display cleared. Goodbye!")
-			time.Sleep(1 * time.Second)
+			hw.Clock.Sleep(1 * time.Second)
 			return
 		}
 		// Show answer
@@ -76,7 +76,7 @@ func (MenuFun) Run(hw *controls.Controls) {
 			if firmware.ShouldExit(hw) {
 				return
 			}
-			time.Sleep(2 * time.Millisecond)
+			hw.Clock.Sleep(2 * time.Millisecond)
 		}
 
 		// At this point we have pressed B1. Check if we should exit or continue in this app
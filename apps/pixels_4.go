@@ -4,7 +4,7 @@ import (
 	"europi/buttons"
 	"europi/controls"
 	"europi/display"
-	"image/color"
+	"europi/graphics"
 	"math"
 	"math/rand"
 	"sync"
@@ -15,71 +15,15 @@ type Pixels4 struct{}
 
 func (Pixels4) Name() string { return "Pixels 4 Loop (v2)" }
 
-// Rect is a simple rectangle struct.
-type Rect struct{ X, Y, W, H int16 }
-
-// rectsOverlapWithSpacing is a placeholder for collision detection.
-func rectsOverlapWithSpacing(r1, r2 Rect) bool {
-	// A simple overlap check with a 2-pixel spacing.
-	return r1.X < r2.X+r2.W+2 && r1.X+r1.W+2 > r2.X &&
-		r1.Y < r2.Y+r2.H+2 && r1.Y+r1.H+2 > r2.Y
-}
-
-// Helper function to draw rectangle outline
-func drawRectangleOutline(ssd interface {
-	SetPixel(x, y int16, c color.RGBA)
-}, x, y, w, h int16) {
-	// Top and bottom edges
-	for i := int16(0); i < w; i++ {
-		ssd.SetPixel(x+i, y, display.ColorWhite)
-		ssd.SetPixel(x+i, y+h-1, display.ColorWhite)
-	}
-	// Left and right edges
-	for i := int16(0); i < h; i++ {
-		ssd.SetPixel(x, y+i, display.ColorWhite)
-		ssd.SetPixel(x+w-1, y+i, display.ColorWhite)
-	}
-}
-
-// plotLine is a placeholder for drawing a line.
-func plotLine(ssd display.ISSD1306Device, x1, y1, x2, y2 int16) {
-	// This is a basic implementation of Bresenham's line algorithm.
-	dx := int16(math.Abs(float64(x2 - x1)))
-	dy := int16(-math.Abs(float64(y2 - y1)))
-	sx := int16(1)
-	if x1 > x2 {
-		sx = -1
-	}
-	sy := int16(1)
-	if y1 > y2 {
-		sy = -1
-	}
-	err := dx + dy
-	for {
-		ssd.SetPixel(x1, y1, display.ColorWhite)
-		if x1 == x2 && y1 == y2 {
-			break
-		}
-		e2 := 2 * err
-		if e2 >= dy {
-			err += dy
-			x1 += sx
-		}
-		if e2 <= dx {
-			err += dx
-			y1 += sy
-		}
-	}
-}
-
 func (Pixels4) Run(hw *controls.Controls) {
 	ssd, ok := hw.Display.GetSSD1306().(display.ISSD1306Device)
 	if !ok {
 		println("No SSD1306 device found, cannot run Pixels app")
 		return
 	}
+	canvas := graphics.New(ssd)
 
-	width, height := int16(128), int16(32)
+	width, height := canvas.Size()
 	modes := []string{"Sine Wave", "Square Wave", "Random Lines", "Random Rectangles"}
 
 	// state holds all shared data between the two goroutines.
@@ -117,7 +61,7 @@ func (Pixels4) Run(hw *controls.Controls) {
 			delayMs = maxMs - (k2 * (maxMs - minMs) / 100)
 		}
 
-		// Ensure a minimum delay to prevent the ticker from panicking.
+		// Ensure a minimum delay so the animation doesn't hog the CPU.
 		if delayMs < 10 {
 			delayMs = 10
 		}
@@ -128,11 +72,12 @@ func (Pixels4) Run(hw *controls.Controls) {
 
 	// Goroutine for polling user inputs. It only writes to the state.
 	go func() {
-		btnMgr := buttons.New(hw.B1, hw.B2)
+		btnMgr := buttons.NewWithClock(hw.B1, hw.B2, hw.Clock)
+		defer btnMgr.Close()
 		lastKnob2Value := -1
 
 		for {
-			time.Sleep(20 * time.Millisecond) // Poll inputs at 50Hz
+			hw.Clock.Sleep(20 * time.Millisecond) // Poll inputs at 50Hz
 
 			mu.Lock()
 			if st.exit {
@@ -173,12 +118,9 @@ func (Pixels4) Run(hw *controls.Controls) {
 	currentDelay := st.frameDelay
 	mu.Unlock()
 
-	ticker := time.NewTicker(currentDelay)
-	defer ticker.Stop()
-
 	for {
-		// 1. Wait for the next tick. The animation is now purely ticker-driven.
-		<-ticker.C
+		// 1. Wait for the next frame, on hw.Clock so the animation can be stepped in tests.
+		hw.Clock.Sleep(currentDelay)
 
 		// 2. Lock the mutex and get a consistent snapshot of the state.
 		mu.Lock()
@@ -200,11 +142,8 @@ func (Pixels4) Run(hw *controls.Controls) {
 		}
 		mu.Unlock()
 
-		// 3. If the frame delay has changed, reset the ticker.
-		if newDelay != currentDelay {
-			ticker.Reset(newDelay)
-			currentDelay = newDelay
-		}
+		// 3. Pick up any change to the frame delay for the next frame.
+		currentDelay = newDelay
 
 		// 4. Perform all drawing using the local state variables.
 		ssd.ClearBuffer()
@@ -212,11 +151,11 @@ func (Pixels4) Run(hw *controls.Controls) {
 		case 0: // Sine Wave
 			for x := int16(0); x < width; x++ {
 				xx := (x + offset) % width
-				y := int16(16 + 12*math.Sin(float64(xx)*2*math.Pi/float64(width)))
-				ssd.SetPixel(x, y, display.ColorWhite)
+				y := int16(float64(height/2) + float64(height*3/8)*math.Sin(float64(xx)*2*math.Pi/float64(width)))
+				canvas.Pixel(x, y)
 			}
 		case 1: // Square Wave
-			highY, lowY := int16(8), int16(24)
+			highY, lowY := height/4, height*3/4
 			period := int16(32)
 			half := period / 2
 			for x := int16(0); x < width; x++ {
@@ -225,11 +164,9 @@ func (Pixels4) Run(hw *controls.Controls) {
 				if xx < half {
 					y = highY
 				}
-				ssd.SetPixel(x, y, display.ColorWhite)
+				canvas.Pixel(x, y)
 				if xx == 0 || xx == half {
-					for y2 := highY; y2 <= lowY; y2++ {
-						ssd.SetPixel(x, y2, display.ColorWhite)
-					}
+					canvas.VLine(x, highY, lowY-highY+1)
 				}
 			}
 		case 2: // Random Lines
@@ -239,29 +176,29 @@ func (Pixels4) Run(hw *controls.Controls) {
 				y1 := int16(rand.Intn(int(height)))
 				x2 := int16(rand.Intn(int(width)))
 				y2 := int16(rand.Intn(int(height)))
-				plotLine(ssd, x1, y1, x2, y2)
+				canvas.Line(x1, y1, x2, y2)
 			}
 		case 3: // Random Rectangles
-			var placedRects []Rect
+			var placedRects []graphics.Rect
 			maxRects := 3 + int(knob2Value/20)
 			for i := 0; i < maxRects*3 && len(placedRects) < maxRects; i++ {
 				w := int16(8 + rand.Intn(12))
 				h := int16(4 + rand.Intn(6))
 				x := int16(rand.Intn(int(width - w)))
 				y := int16(rand.Intn(int(height - h)))
-				newRect := Rect{x, y, w, h}
+				newRect := graphics.Rect{X: x, Y: y, W: w, H: h}
 				hasOverlap := false
 				for _, existing := range placedRects {
-					if rectsOverlapWithSpacing(newRect, existing) {
+					if newRect.Overlaps(existing, 2) {
 						hasOverlap = true
 						break
 					}
 				}
 				if !hasOverlap {
 					if rand.Intn(3) == 0 {
-						drawRectangleOutline(ssd, x, y, w, h)
+						canvas.Rect(x, y, w, h)
 					} else {
-						ssd.FillRectangle(x, y, w, h, display.ColorWhite)
+						canvas.FillRect(x, y, w, h)
 					}
 					placedRects = append(placedRects, newRect)
 				}
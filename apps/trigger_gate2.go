@@ -3,6 +3,7 @@ package apps
 import (
 	"europi/buttons"
 	"europi/controls"
+	"europi/firmware"
 	"fmt"
 	"runtime"
 	"sync"
@@ -21,35 +22,28 @@ and the gate starting with k2. Handy for converting short triggers (e.g. 1ms)
 into longer gates (e.g. 10ms) as some eurorack modules don't like short
 triggers.
 
-TriggerGateDelay2 is a version of TriggerGateDelay that uses a time-based approach
-instead of a scheduler. It tracks the next gate on and off times using time.Time fields.
-This allows for precise timing without the overhead of a scheduler, making it suitable
-for applications where timing accuracy is critical.
+TriggerGateDelay2 is a version of TriggerGateDelay that hands each gate to
+hw.Scheduler, timed from the trigger's timestamp in the DIN interrupt. The
+scheduler turns CV1 on after the delay and off again after the pulse width, so
+the gate timing doesn't depend on how often the main loop runs.
 */
 
 type TriggerGateDelay2 struct{}
 
 func (TriggerGateDelay2) Name() string { return "Trigger Gate 2" }
 
-// TGDState2 holds the application's state. The scheduler has been replaced
-// with time.Time fields to track when future events should occur.
+// TGDState2 holds the application's state. Gates are timed by hw.Scheduler.
 type TGDState2 struct {
 	hw *controls.Controls
 
-	// --- Timing State ---
-	// These fields replace the scheduler. A zero time.Time means no event is scheduled.
-	nextGateOnTime  time.Time
-	nextGateOffTime time.Time
-
 	// --- Digital Input State ---
 	dinPulseWidth time.Duration
 	dinPeriod     time.Duration
 	dinHz         float64
 	lastTrigger   time.Time
-	edgeEvents    chan bool // Channel to receive edge events from the ISR
+	din           *firmware.DINSubscription // DIN edges, timestamped in the ISR
 
 	// --- Gate Output State ---
-	gateIsHigh         bool // True if the gate is currently high
 	gateRunning        bool // True if the gate logic is enabled
 	gateDelay          time.Duration
 	gatePulseWidth     time.Duration
@@ -60,11 +54,6 @@ type TGDState2 struct {
 	running        bool
 	updateUI       bool
 	lastK1, lastK2 int
-
-	// --- Gate Timing Diagnostics ---
-	prevGateOnTime  time.Time
-	prevGateOffTime time.Time
-	expectedGateMs  int
 }
 
 // max returns the larger of two time.Duration values.
@@ -91,8 +80,8 @@ func (TriggerGateDelay2) Run(hw *controls.Controls) {
 	state := &TGDState2{
 		hw:                 hw,
 		running:            true,
-		btnMgr:             buttons.New(hw.B1, hw.B2),
-		edgeEvents:         make(chan bool, 8),
+		btnMgr:             buttons.NewWithClock(hw.B1, hw.B2, hw.Clock),
+		din:                firmware.SubscribeDIN(hw, 8),
 		afterOffSettlingMs: 1 * time.Millisecond, // 1ms settling time.
 	}
 
@@ -102,10 +91,13 @@ func (TriggerGateDelay2) Run(hw *controls.Controls) {
 	// Defer the cleanup logic to run when the 'Run' function exits.
 	defer func() {
 		println("Cleaning up...")
-		// Disable the hardware interrupt to prevent it from firing after the app has exited.
-		hw.DIN.UnsetInterrupt()
+		// Unsubscribe from DIN, removing the interrupt once nobody else is listening.
+		state.din.Close()
+		hw.Scheduler.Cancel(hw.CV1)
+		state.btnMgr.Close()
 		// Wait for all background goroutines managed by the WaitGroup to finish.
 		wg.Wait()
+		state.saveState()
 		println("Cleanup complete.")
 	}()
 
@@ -113,44 +105,19 @@ func (TriggerGateDelay2) Run(hw *controls.Controls) {
 	state.gateRunning = true
 	state.gatePulseWidth = 10 * time.Millisecond
 	state.gateDelay = 0
+	state.loadState()
 	state.updateUI = true // Force initial screen draw
 
-	// This helper function sends an event from the ISR to the channel.
-	// The select/default pattern guarantees the ISR never blocks.
-	sendEvent := func(isRise bool) {
-		select {
-		case state.edgeEvents <- isRise:
-			// Event sent successfully.
-		default:
-			// Channel was full, so the event is dropped.
-			// This is a safeguard against the main loop getting stuck.
-		}
-	}
-
-	// Configure the digital input to send events to our channel.
-	hw.DIN.SetEdgeHandlers(
-		func() { // Rise handler
-			if state.gateRunning {
-				sendEvent(true)
-			}
-		},
-		func() { // Fall handler
-			if state.gateRunning {
-				sendEvent(false)
-			}
-		},
-	)
-
 	// --- Background Goroutine for Low-Priority Tasks ---
 	// This goroutine handles UI updates and state saving, preventing them
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		lastDisplay := time.Now()
-		lastSave := time.Now()
+		lastDisplay := hw.Clock.Now()
+		lastSave := hw.Clock.Now()
 
 		for state.running {
-			now := time.Now()
+			now := hw.Clock.Now()
 
 			if now.Sub(lastDisplay) > 150*time.Millisecond {
 				state.drawScreen()
@@ -168,27 +135,15 @@ func (TriggerGateDelay2) Run(hw *controls.Controls) {
 
 	// --- Main Application Loop (High-Priority Tasks Only) ---
 	for state.running {
-		now := time.Now()
-
-		// --- 1. Process Time-Critical Events ---
-		// Check if it's time to turn the gate on.
-		if !state.nextGateOnTime.IsZero() && now.After(state.nextGateOnTime) {
-			state.gateOn()
-			state.nextGateOnTime = time.Time{} // Clear the event so it doesn't re-fire.
-		}
-
-		// Check if it's time to turn the gate off.
-		if !state.nextGateOffTime.IsZero() && now.After(state.nextGateOffTime) {
-			state.gateOff()
-			state.nextGateOffTime = time.Time{} // Clear the event.
-		}
-
-		// --- 2. Process Asynchronous Inputs (Digital In) ---
+		// --- 1. Process Asynchronous Inputs (Digital In) ---
 		// Perform a non-blocking check for DIN events.
 		select {
-		case isRise := <-state.edgeEvents:
-			if isRise {
-				triggerTime := time.Now()
+		case ev := <-state.din.Events():
+			if !state.gateRunning {
+				break
+			}
+			if ev.Rising {
+				triggerTime := ev.At
 				if !state.lastTrigger.IsZero() {
 					state.dinPeriod = triggerTime.Sub(state.lastTrigger)
 					calcHz2(state)
@@ -197,28 +152,25 @@ func (TriggerGateDelay2) Run(hw *controls.Controls) {
 				state.lastTrigger = triggerTime
 
 				delay := state.gateDelay
-				// If a trigger arrives while the gate is already high (a re-trigger)...
-				if state.gateIsHigh {
-					state.gateOff()                     // ...turn the current gate off immediately.
-					state.nextGateOffTime = time.Time{} // ...and cancel its scheduled turn-off time.
+				// If a trigger arrives while a gate is high or pending (a re-trigger)...
+				if hw.Scheduler.Active(hw.CV1) {
+					hw.Scheduler.Cancel(hw.CV1) // ...turn the current gate off immediately.
 					// Use a small settling delay to ensure the output signal falls cleanly.
 					delay = max(state.gateDelay, state.afterOffSettlingMs)
 				}
 
 				// Schedule the new gate to turn on after the calculated delay.
-				if state.gateRunning {
-					state.nextGateOnTime = triggerTime.Add(delay)
-				}
+				hw.Scheduler.ScheduleGate(hw.CV1, triggerTime.Add(delay), state.gatePulseWidth)
 
 			} else {
 				// Falling edge: calculate the input pulse width.
-				state.dinPulseWidth = time.Since(state.lastTrigger)
+				state.dinPulseWidth = ev.At.Sub(state.lastTrigger)
 			}
 		default:
 			// No event waiting, so we immediately continue.
 		}
 
-		// --- 3. Process Polled Inputs (Buttons & Knobs) ---
+		// --- 2. Process Polled Inputs (Buttons & Knobs) ---
 		switch state.btnMgr.Update() {
 		case buttons.B1Press:
 			state.gateRunning = !state.gateRunning
@@ -251,47 +203,6 @@ func (TriggerGateDelay2) Run(hw *controls.Controls) {
 	}
 }
 
-// gateOn turns the gate output high and schedules it to turn off.
-func (s *TGDState2) gateOn() {
-	now := time.Now()
-
-	// --- Sanity Checks ---
-	if !s.prevGateOffTime.IsZero() && now.Sub(s.prevGateOffTime) < s.gatePulseWidth {
-		fmt.Printf("[WARN] Gate retriggered %dms after gate off\n", now.Sub(s.prevGateOffTime).Milliseconds())
-	}
-	if !s.lastTrigger.IsZero() && now.Sub(s.lastTrigger) > s.gateDelay+(5*time.Millisecond) {
-		fmt.Printf("[ERROR] Gate triggered too late after last trigger: by %dms\n", now.Sub(s.lastTrigger).Milliseconds())
-	}
-
-	s.prevGateOnTime = now
-	s.gateIsHigh = true
-	s.hw.CV1.On()
-
-	// For diagnostics
-	s.expectedGateMs = int(s.gatePulseWidth.Milliseconds())
-
-	// Schedule the gate to turn off.
-	s.nextGateOffTime = now.Add(s.gatePulseWidth)
-}
-
-// gateOff turns the gate output low.
-func (s *TGDState2) gateOff() {
-	now := time.Now()
-	duration := now.Sub(s.prevGateOnTime).Milliseconds()
-	s.prevGateOffTime = now
-
-	// --- Sanity Checks ---
-	if duration > int64(s.expectedGateMs)+2 {
-		fmt.Printf("[ERROR] Gate lasted too long: %dms > expected %dms\n", duration, s.expectedGateMs)
-	}
-	if duration < int64(s.expectedGateMs)-2 {
-		fmt.Printf("[ERROR] Gate too short: %dms < expected %dms\n", duration, s.expectedGateMs)
-	}
-
-	s.hw.CV1.Off()
-	s.gateIsHigh = false
-}
-
 // drawScreen updates the OLED display if the state has changed.
 func (s *TGDState2) drawScreen() {
 	if s.updateUI {
@@ -310,8 +221,36 @@ func (s *TGDState2) drawScreen() {
 	// Note: Rescheduling is now handled in the main loop.
 }
 
-// saveState is a stub for saving persistent state.
+// loadState restores the gate settings saved by a previous run. The knob
+// positions are taken as the starting point so the restored values stay in
+// effect until a knob is actually moved.
+func (s *TGDState2) loadState() {
+	store := s.hw.Settings
+	if store == nil {
+		return
+	}
+	s.gateRunning = store.GetBool("tgd2.running", s.gateRunning)
+	if ms := store.GetInt("tgd2.width_ms", -1); ms >= 0 {
+		s.gatePulseWidth = time.Duration(ms) * time.Millisecond
+		s.lastK1 = s.hw.K1.Value()
+	}
+	if ms := store.GetInt("tgd2.delay_ms", -1); ms >= 0 {
+		s.gateDelay = time.Duration(ms) * time.Millisecond
+		s.lastK2 = s.hw.K2.Value()
+	}
+}
+
+// saveState persists the gate settings. The store only writes to flash when
+// something actually changed, so calling this periodically is cheap.
 func (s *TGDState2) saveState() {
-	// fmt.Println("Saving state...")
-	// Note: Rescheduling is now handled in the main loop.
+	store := s.hw.Settings
+	if store == nil {
+		return
+	}
+	store.SetBool("tgd2.running", s.gateRunning)
+	store.SetInt("tgd2.width_ms", int(s.gatePulseWidth.Milliseconds()))
+	store.SetInt("tgd2.delay_ms", int(s.gateDelay.Milliseconds()))
+	if err := store.Save(); err != nil {
+		println("Trigger Gate 2: saving settings failed:", err.Error())
+	}
 }
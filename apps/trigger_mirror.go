@@ -3,6 +3,7 @@ package apps
 import (
 	"europi/buttons"
 	"europi/controls"
+	"europi/firmware"
 	"math/rand"
 	"strconv"
 	"sync"
@@ -19,9 +20,8 @@ type TMState struct {
 	running     bool
 	btnMgr      *buttons.ButtonManager
 	gateRunning bool
-	gateIsHigh  bool
 	uniqueId    int
-	edgeEvents  chan bool // true for rise, false for fall
+	din         *firmware.DINSubscription
 }
 
 func (TriggerMirror) Run(hw *controls.Controls) {
@@ -29,36 +29,11 @@ func (TriggerMirror) Run(hw *controls.Controls) {
 		hw:          hw,
 		running:     true,
 		gateRunning: true,
-		btnMgr:      buttons.New(hw.B1, hw.B2),
-		gateIsHigh:  false,
+		btnMgr:      buttons.NewWithClock(hw.B1, hw.B2, hw.Clock),
 		uniqueId:    rand.Int(),
-		edgeEvents:  make(chan bool, 8),
+		din:         firmware.SubscribeDIN(hw, 8),
 	}
 
-	// This helper function sends an event from the ISR to the channel.
-	// The select/default pattern guarantees the ISR never blocks, even if the channel were full.
-	sendEvent := func(isRise bool) {
-		select {
-		case state.edgeEvents <- isRise:
-			// Event sent
-		default:
-			// Channel full, event dropped (safeguard)
-		}
-	}
-
-	hw.DIN.SetEdgeHandlers(
-		func() { // Rise handler
-			if state.gateRunning {
-				sendEvent(true)
-			}
-		},
-		func() { // Fall handler
-			if state.gateRunning {
-				sendEvent(false)
-			}
-		},
-	)
-
 	// Launch drawscreen in a separate goroutine
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -66,7 +41,7 @@ func (TriggerMirror) Run(hw *controls.Controls) {
 		defer wg.Done()
 		for state.running {
 			state.drawScreen()
-			time.Sleep(100 * time.Millisecond)
+			hw.Clock.Sleep(100 * time.Millisecond)
 		}
 	}()
 
@@ -77,13 +52,14 @@ func (TriggerMirror) Run(hw *controls.Controls) {
 		// Step 1: Perform a NON-BLOCKING check for DIN events.
 		// The 'default' case means the loop doesn't wait here if the channel is empty.
 		select {
-		case isRise := <-state.edgeEvents:
-			if isRise {
+		case ev := <-state.din.Events():
+			if !state.gateRunning {
+				break
+			}
+			if ev.Rising {
 				state.hw.CV1.On()
-				state.gateIsHigh = true
 			} else {
 				state.hw.CV1.Off()
-				state.gateIsHigh = false
 			}
 		default:
 			// No event waiting, so we immediately continue to the next step.
@@ -105,7 +81,8 @@ func (TriggerMirror) Run(hw *controls.Controls) {
 	}
 
 	// Cleanup
-	hw.DIN.UnsetInterrupt()
+	state.din.Close()
+	state.btnMgr.Close()
 	wg.Wait()
 }
 
@@ -114,7 +91,7 @@ func (s *TMState) drawScreen() {
 	s.hw.Display.ClearBuffer()
 	s.hw.Display.WriteLine(0, "Trigger Mirror 2")
 	s.hw.Display.WriteLine(1, "Running: "+strconv.FormatBool(s.gateRunning))
-	if s.gateIsHigh {
+	if s.hw.CV1.IsHigh() {
 		s.hw.Display.WriteLine(2, "CV1 is ON")
 	} else {
 		s.hw.Display.WriteLine(2, "CV1 is OFF")
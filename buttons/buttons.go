@@ -1,83 +1,352 @@
 package buttons
 
-import "time"
+import (
+	"europi/clock"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 // DigitalInput abstracts a physical button.
 type DigitalInput interface {
 	Pressed() bool
 }
 
-// Event represents a button press event.
+// EdgeInput is a button that can also report presses and releases from an
+// interrupt (controls.IButton). ButtonManager uses the interrupt when available
+// so short presses between two Update calls are not missed.
+type EdgeInput interface {
+	DigitalInput
+	SetEdgeHandlers(pressCallback func(), releaseCallback func())
+	UnsetInterrupt()
+}
+
+// edgeEvent is a button edge captured, with its timestamp, in the interrupt handler.
+type edgeEvent struct {
+	idx     int
+	pressed bool
+	at      time.Time
+}
+
+// Event represents a button gesture.
 type Event int
 
 const (
 	None Event = iota
+	// B1Press/B2Press: released, however long it was held (not part of a chord)
 	B1Press
 	B2Press
+	// B1LongPress/B2LongPress: released after being held for at least Thresholds.LongPress.
+	// Follows the Press event, so apps only interested in presses needn't care how long they are.
+	B1LongPress
+	B2LongPress
+	// B1DoublePress/B2DoublePress: a second short press within Thresholds.DoublePress
+	// of the first. Follows the Press event of the second press.
+	B1DoublePress
+	B2DoublePress
+	// B1HoldStart/B2HoldStart: still held after Thresholds.Hold
+	B1HoldStart
+	B2HoldStart
+	// B1HoldRepeat/B2HoldRepeat: repeats every Thresholds.Repeat while held after HoldStart
+	B1HoldRepeat
+	B2HoldRepeat
+	// B1HoldEnd/B2HoldEnd: released after HoldStart
+	B1HoldEnd
+	B2HoldEnd
+	// Chord: both buttons went down together. Their releases do not produce press events.
+	Chord
 )
 
+var eventNames = [...]string{
+	"None",
+	"B1Press", "B2Press",
+	"B1LongPress", "B2LongPress",
+	"B1DoublePress", "B2DoublePress",
+	"B1HoldStart", "B2HoldStart",
+	"B1HoldRepeat", "B2HoldRepeat",
+	"B1HoldEnd", "B2HoldEnd",
+	"Chord",
+}
+
+func (e Event) String() string {
+	if e >= 0 && int(e) < len(eventNames) {
+		return eventNames[e]
+	}
+	return "Unknown"
+}
+
+// forButton returns the B1 or B2 variant of a per-button event, b1Event being the B1 variant.
+func forButton(b1Event Event, idx int) Event {
+	return b1Event + Event(idx)
+}
+
+// Thresholds configures gesture timing.
+type Thresholds struct {
+	Debounce    time.Duration // state changes closer together than this are ignored
+	LongPress   time.Duration // minimum hold for a release to be a long press
+	Hold        time.Duration // hold time before HoldStart
+	Repeat      time.Duration // HoldRepeat interval, 0 disables repeats
+	DoublePress time.Duration // maximum gap between two presses for a double press
+	BothHeld    time.Duration // how long both buttons must be held for BothHeld
+}
+
+// DefaultThresholds returns the thresholds used by New.
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		Debounce:    50 * time.Millisecond,
+		LongPress:   600 * time.Millisecond,
+		Hold:        600 * time.Millisecond,
+		Repeat:      150 * time.Millisecond,
+		DoublePress: 300 * time.Millisecond,
+		BothHeld:    1 * time.Second,
+	}
+}
+
+// buttonState tracks the gesture state of one button.
+type buttonState struct {
+	input       DigitalInput
+	pressed     bool
+	changedAt   time.Time // last accepted state change, for debounce
+	start       time.Time // when the current press started
+	holding     bool      // HoldStart has been emitted for the current press
+	nextRepeat  time.Time
+	chorded     bool      // the current press is part of a chord
+	lastPressAt time.Time // release time of the last short press, for double press
+}
+
+// ButtonManager turns the raw state of the two buttons into gesture events.
+// Call Update regularly; it returns one event per call, queueing any extra
+// events that happened at the same time for the following calls.
+//
+// If both buttons support edge interrupts (EdgeInput), the edges are captured
+// with their timestamps in the interrupt handler and replayed by Update, so
+// gestures are timed from the moment the button moved rather than from when
+// the app got round to calling Update. The button state is still polled in
+// Update as a safety net for dropped edges. Call Close when done with the
+// manager to remove the interrupt handlers. If managers overlap, the newest
+// one gets the interrupt until it's closed (see edgeOwners).
 type ButtonManager struct {
-	b1, b2         DigitalInput
-	b1Pressed      bool
-	b2Pressed      bool
-	b1ChangedAt    time.Time
-	b2ChangedAt    time.Time
-	b1Start        time.Time
-	b2Start        time.Time
-	debounce       time.Duration
-	heldThreshold  time.Duration
+	Thresholds Thresholds
+	buttons    [2]buttonState
+	pending    []Event
+	clock      clock.Clock
+	edges      chan edgeEvent // nil when polling only
+	dropped    atomic.Uint32
 }
 
 func New(b1, b2 DigitalInput) *ButtonManager {
-	return &ButtonManager{
-		b1:            b1,
-		b2:            b2,
-		debounce:      50 * time.Millisecond,
-		heldThreshold: 1 * time.Second,
+	return NewWithClock(b1, b2, clock.Real{})
+}
+
+// NewWithClock creates a ButtonManager that measures debounce and hold times with clk.
+func NewWithClock(b1, b2 DigitalInput, clk clock.Clock) *ButtonManager {
+	bm := &ButtonManager{
+		Thresholds: DefaultThresholds(),
+		buttons:    [2]buttonState{{input: b1}, {input: b2}},
+		clock:      clk,
+	}
+	e1, ok1 := b1.(EdgeInput)
+	e2, ok2 := b2.(EdgeInput)
+	if ok1 && ok2 {
+		bm.edges = make(chan edgeEvent, 16)
+		edgeOwnersMu.Lock()
+		defer edgeOwnersMu.Unlock()
+		for i, e := range []EdgeInput{e1, e2} {
+			edgeOwners[e] = append(edgeOwners[e], bm)
+			bm.setEdgeHandlers(i, e)
+		}
+	}
+	return bm
+}
+
+// edgeOwners stacks the managers using each button's interrupt, newest last.
+// A button has one set of edge handlers, so only the newest manager gets the
+// edges, e.g. a menu opened from inside an app, and the others poll. When it
+// closes, the manager before it gets the interrupt back.
+var (
+	edgeOwnersMu sync.Mutex
+	edgeOwners   = map[EdgeInput][]*ButtonManager{}
+)
+
+func (bm *ButtonManager) setEdgeHandlers(idx int, e EdgeInput) {
+	e.SetEdgeHandlers(
+		func() { bm.captureEdge(idx, true) },
+		func() { bm.captureEdge(idx, false) },
+	)
+}
+
+// captureEdge runs in the interrupt handler. The select/default pattern
+// guarantees the ISR never blocks.
+func (bm *ButtonManager) captureEdge(idx int, pressed bool) {
+	select {
+	case bm.edges <- edgeEvent{idx: idx, pressed: pressed, at: bm.clock.Now()}:
+	default:
+		bm.dropped.Add(1)
+	}
+}
+
+// Close removes the interrupt handlers installed by the manager, handing the
+// interrupt back to the manager that had it before, if it's still open.
+func (bm *ButtonManager) Close() {
+	if bm.edges == nil {
+		return
+	}
+	edgeOwnersMu.Lock()
+	defer edgeOwnersMu.Unlock()
+	for _, b := range bm.buttons {
+		e := b.input.(EdgeInput)
+		owners := edgeOwners[e]
+		i := len(owners) - 1
+		for i >= 0 && owners[i] != bm {
+			i--
+		}
+		if i < 0 {
+			continue // already closed
+		}
+		newest := i == len(owners)-1
+		owners = append(owners[:i:i], owners[i+1:]...)
+		switch {
+		case len(owners) == 0:
+			delete(edgeOwners, e)
+			e.UnsetInterrupt()
+		case newest:
+			edgeOwners[e] = owners
+			prev := owners[len(owners)-1]
+			for idx, pb := range prev.buttons {
+				if pb.input == b.input {
+					prev.setEdgeHandlers(idx, e)
+					break
+				}
+			}
+		default:
+			edgeOwners[e] = owners // a newer manager still has the interrupt
+		}
 	}
 }
 
-// Update reads button states and returns any press events.
+// Dropped returns the number of interrupt edges dropped because Update was not
+// called often enough to drain them.
+func (bm *ButtonManager) Dropped() int {
+	return int(bm.dropped.Load())
+}
+
+// Update processes button edges and returns the next gesture event, or None.
 func (bm *ButtonManager) Update() Event {
-	now := time.Now()
-
-	// --- B1 logic ---
-	b1Now := bm.b1.Pressed()
-	if b1Now != bm.b1Pressed && now.Sub(bm.b1ChangedAt) > bm.debounce {
-		bm.b1ChangedAt = now
-		bm.b1Pressed = b1Now
-		if b1Now {
-			bm.b1Start = now
-		} else if !bm.BothHeld() {
-			return B1Press
+	// Replay the edges captured by the interrupt handler, with their own timestamps
+	for drained := false; bm.edges != nil && !drained; {
+		select {
+		case ev := <-bm.edges:
+			bm.edge(ev.idx, ev.pressed, ev.at)
+		default:
+			drained = true
 		}
 	}
+	now := bm.clock.Now()
+	for i := range bm.buttons {
+		bm.edge(i, bm.buttons[i].input.Pressed(), now)
+	}
+	bm.tick(now)
+	return bm.next()
+}
 
-	// --- B2 logic ---
-	b2Now := bm.b2.Pressed()
-	if b2Now != bm.b2Pressed && now.Sub(bm.b2ChangedAt) > bm.debounce {
-		bm.b2ChangedAt = now
-		bm.b2Pressed = b2Now
-		if b2Now {
-			bm.b2Start = now
-		} else if !bm.BothHeld() {
-			return B2Press
+// edge processes a (possibly unchanged) button state observed at time at.
+func (bm *ButtonManager) edge(idx int, pressed bool, at time.Time) {
+	b := &bm.buttons[idx]
+	if pressed == b.pressed || at.Sub(b.changedAt) <= bm.Thresholds.Debounce {
+		return
+	}
+	b.changedAt = at
+	b.pressed = pressed
+	other := &bm.buttons[1-idx]
+
+	if pressed {
+		b.start = at
+		b.holding = false
+		b.chorded = false
+		if other.pressed {
+			b.chorded = true
+			other.chorded = true
+			if other.holding {
+				bm.emit(forButton(B1HoldEnd, 1-idx))
+				other.holding = false
+			}
+			bm.emit(Chord)
 		}
+		return
 	}
 
-	return None
+	// Released
+	if b.chorded {
+		b.chorded = false
+		b.holding = false
+		return
+	}
+	held := at.Sub(b.start)
+	if b.holding {
+		bm.emit(forButton(B1HoldEnd, idx))
+		b.holding = false
+	}
+	bm.emit(forButton(B1Press, idx))
+	if held >= bm.Thresholds.LongPress {
+		bm.emit(forButton(B1LongPress, idx))
+		b.lastPressAt = time.Time{} // a long press doesn't start a double press
+		return
+	}
+	if !b.lastPressAt.IsZero() && at.Sub(b.lastPressAt) <= bm.Thresholds.DoublePress {
+		bm.emit(forButton(B1DoublePress, idx))
+		b.lastPressAt = time.Time{} // a third press starts a new pair
+	} else {
+		b.lastPressAt = at
+	}
+}
+
+// tick emits the time based hold events.
+func (bm *ButtonManager) tick(now time.Time) {
+	for i := range bm.buttons {
+		b := &bm.buttons[i]
+		if !b.pressed || b.chorded {
+			continue
+		}
+		if !b.holding {
+			if now.Sub(b.start) >= bm.Thresholds.Hold {
+				b.holding = true
+				b.nextRepeat = b.start.Add(bm.Thresholds.Hold + bm.Thresholds.Repeat)
+				bm.emit(forButton(B1HoldStart, i))
+			}
+			continue
+		}
+		if bm.Thresholds.Repeat > 0 && !now.Before(b.nextRepeat) {
+			b.nextRepeat = b.nextRepeat.Add(bm.Thresholds.Repeat)
+			bm.emit(forButton(B1HoldRepeat, i))
+		}
+	}
+}
+
+func (bm *ButtonManager) emit(e Event) {
+	bm.pending = append(bm.pending, e)
+}
+
+func (bm *ButtonManager) next() Event {
+	if len(bm.pending) == 0 {
+		return None
+	}
+	e := bm.pending[0]
+	bm.pending = bm.pending[1:]
+	return e
 }
 
-// BothHeld returns true if both buttons have been held for threshold duration.
+// BothHeld returns true if both buttons have been held for the BothHeld threshold.
 func (bm *ButtonManager) BothHeld() bool {
-	if bm.b1Pressed && bm.b2Pressed {
-		since := time.Since(max(bm.b1Start, bm.b2Start))
-		return since >= bm.heldThreshold
+	b1, b2 := &bm.buttons[0], &bm.buttons[1]
+	if b1.pressed && b2.pressed {
+		since := bm.clock.Now().Sub(later(b1.start, b2.start))
+		return since >= bm.Thresholds.BothHeld
 	}
 	return false
 }
 
-func max(a, b time.Time) time.Time {
+// later returns whichever of a and b is later.
+func later(a, b time.Time) time.Time {
 	if a.After(b) {
 		return a
 	}
@@ -0,0 +1,225 @@
+// Gesture recognition tests, driven by mock buttons and a virtual clock
+package buttons
+
+import (
+	"europi/clock"
+	"europi/controls"
+	"testing"
+	"time"
+)
+
+type harness struct {
+	t      *testing.T
+	clk    *clock.Virtual
+	b1, b2 *controls.MockButton
+	bm     *ButtonManager
+	events []Event
+}
+
+func newHarness(t *testing.T) *harness {
+	h := &harness{t: t, clk: clock.NewVirtual(time.Time{}), b1: &controls.MockButton{}, b2: &controls.MockButton{}}
+	h.bm = NewWithClock(h.b1, h.b2, h.clk)
+	return h
+}
+
+// run polls the manager every millisecond for d, collecting events.
+func (h *harness) run(d time.Duration) {
+	h.clk.Step(d, time.Millisecond, func(time.Time) {
+		for e := h.bm.Update(); e != None; e = h.bm.Update() {
+			h.events = append(h.events, e)
+		}
+	})
+}
+
+func (h *harness) press(b *controls.MockButton, d time.Duration) {
+	b.SetPressed(true)
+	h.run(d)
+	b.SetPressed(false)
+}
+
+func (h *harness) expect(want ...Event) {
+	h.t.Helper()
+	if len(h.events) != len(want) {
+		h.t.Fatalf("Expected events %v, got %v", want, h.events)
+	}
+	for i := range want {
+		if h.events[i] != want[i] {
+			h.t.Fatalf("Expected events %v, got %v", want, h.events)
+		}
+	}
+	h.events = nil
+}
+
+func TestShortPress(t *testing.T) {
+	h := newHarness(t)
+	h.press(h.b1, 100*time.Millisecond)
+	h.run(500 * time.Millisecond)
+	h.expect(B1Press)
+	h.press(h.b2, 100*time.Millisecond)
+	h.run(500 * time.Millisecond)
+	h.expect(B2Press)
+}
+
+func TestDebounce(t *testing.T) {
+	h := newHarness(t)
+	h.b1.SetPressed(true)
+	h.run(10 * time.Millisecond)
+	h.b1.SetPressed(false) // bounce, too soon after the press to count
+	h.run(10 * time.Millisecond)
+	h.b1.SetPressed(true)
+	h.run(100 * time.Millisecond)
+	h.b1.SetPressed(false)
+	h.run(10 * time.Millisecond)
+	h.expect(B1Press)
+}
+
+func TestDoublePress(t *testing.T) {
+	h := newHarness(t)
+	h.press(h.b1, 80*time.Millisecond)
+	h.run(150 * time.Millisecond)
+	h.press(h.b1, 80*time.Millisecond)
+	h.run(10 * time.Millisecond)
+	h.expect(B1Press, B1Press, B1DoublePress)
+
+	// Too slow for a double press
+	h.run(time.Second)
+	h.press(h.b2, 80*time.Millisecond)
+	h.run(400 * time.Millisecond)
+	h.press(h.b2, 80*time.Millisecond)
+	h.run(10 * time.Millisecond)
+	h.expect(B2Press, B2Press)
+}
+
+func TestLongPressHoldAndRepeat(t *testing.T) {
+	h := newHarness(t)
+	h.bm.Thresholds.Repeat = 100 * time.Millisecond
+	h.press(h.b1, 900*time.Millisecond) // hold starts at 600ms, repeats at 700, 800, 900
+	h.run(10 * time.Millisecond)
+	h.expect(B1HoldStart, B1HoldRepeat, B1HoldRepeat, B1HoldRepeat, B1HoldEnd, B1Press, B1LongPress)
+
+	h.bm.Thresholds.Repeat = 0
+	h.bm.Thresholds.Hold = 2 * time.Second
+	h.press(h.b2, 700*time.Millisecond) // long press without reaching hold
+	h.run(10 * time.Millisecond)
+	h.expect(B2Press, B2LongPress)
+
+	// A short press straight after a long one isn't a double press
+	h.run(100 * time.Millisecond)
+	h.press(h.b2, 80*time.Millisecond)
+	h.run(10 * time.Millisecond)
+	h.expect(B2Press)
+}
+
+// The press edge is timed by the interrupt, so repeats are due exactly every
+// Repeat from the press. A repeat due at the instant of the release still
+// fires, the button having been held until then, one due after it doesn't.
+func TestHoldRepeatAtRelease(t *testing.T) {
+	h := newHarness(t)
+	h.bm.Thresholds.Repeat = 100 * time.Millisecond
+	h.press(h.b1, 899*time.Millisecond)
+	h.run(10 * time.Millisecond)
+	h.expect(B1HoldStart, B1HoldRepeat, B1HoldRepeat, B1HoldEnd, B1Press, B1LongPress)
+
+	h.run(100 * time.Millisecond)
+	h.press(h.b1, 800*time.Millisecond)
+	h.run(10 * time.Millisecond)
+	h.expect(B1HoldStart, B1HoldRepeat, B1HoldRepeat, B1HoldEnd, B1Press, B1LongPress)
+}
+
+func TestChord(t *testing.T) {
+	h := newHarness(t)
+	h.b1.SetPressed(true)
+	h.run(100 * time.Millisecond)
+	h.b2.SetPressed(true)
+	h.run(100 * time.Millisecond)
+	h.b1.SetPressed(false)
+	h.b2.SetPressed(false)
+	h.run(100 * time.Millisecond)
+	h.expect(Chord)
+}
+
+func TestBothHeld(t *testing.T) {
+	h := newHarness(t)
+	h.b1.SetPressed(true)
+	h.b2.SetPressed(true)
+	h.run(999 * time.Millisecond)
+	if h.bm.BothHeld() {
+		t.Fatal("BothHeld true before threshold")
+	}
+	h.run(2 * time.Millisecond)
+	if !h.bm.BothHeld() {
+		t.Fatal("BothHeld false after threshold")
+	}
+	h.expect(Chord)
+}
+
+func TestInterruptCatchesQuickTap(t *testing.T) {
+	h := newHarness(t)
+	// Press and release entirely between two Updates: polling alone would miss it
+	h.b1.SetPressed(true)
+	h.clk.Advance(80 * time.Millisecond)
+	h.b1.SetPressed(false)
+	h.run(10 * time.Millisecond)
+	h.expect(B1Press)
+
+	// Timing comes from the interrupt, not from when Update gets called
+	h.b2.SetPressed(true)
+	h.clk.Advance(700 * time.Millisecond)
+	h.b2.SetPressed(false)
+	h.clk.Advance(time.Second)
+	h.run(10 * time.Millisecond)
+	h.expect(B2Press, B2LongPress)
+
+	// After Close the manager falls back to polling
+	h.bm.Close()
+	h.b1.SetPressed(true)
+	h.clk.Advance(80 * time.Millisecond)
+	h.b1.SetPressed(false)
+	h.run(10 * time.Millisecond)
+	h.expect()
+	if h.bm.Dropped() != 0 {
+		t.Errorf("Expected no dropped edges, got %d", h.bm.Dropped())
+	}
+}
+
+func TestOverlappingManagers(t *testing.T) {
+	h := newHarness(t)
+	outer := h.bm
+	inner := NewWithClock(h.b1, h.b2, h.clk)
+	// tap presses B1 between two Updates, which only the interrupt catches
+	tap := func(bm *ButtonManager) Event {
+		h.b1.SetPressed(true)
+		h.clk.Advance(80 * time.Millisecond)
+		h.b1.SetPressed(false)
+		h.clk.Advance(100 * time.Millisecond)
+		return bm.Update()
+	}
+
+	// The newest manager has the interrupt, the outer one only polls meanwhile
+	if e := tap(inner); e != B1Press {
+		t.Errorf("Expected the inner manager to catch the tap, got %v", e)
+	}
+	if e := outer.Update(); e != None {
+		t.Errorf("Expected the outer manager to miss the tap, got %v", e)
+	}
+
+	// Closing the inner manager hands the interrupt back to the outer one
+	inner.Close()
+	inner.Close() // closing twice is harmless
+	if e := tap(outer); e != B1Press {
+		t.Errorf("Expected the outer manager to get the interrupt back, got %v", e)
+	}
+
+	// Closing an older manager leaves the newer one's handlers alone
+	inner = NewWithClock(h.b1, h.b2, h.clk)
+	outer.Close()
+	if e := tap(inner); e != B1Press {
+		t.Errorf("Expected the inner manager to keep the interrupt, got %v", e)
+	}
+	inner.Close()
+	edgeOwnersMu.Lock()
+	defer edgeOwnersMu.Unlock()
+	if len(edgeOwners[h.b1]) != 0 || len(edgeOwners[h.b2]) != 0 {
+		t.Error("Expected the buttons to have no interrupt owners once all managers closed")
+	}
+}
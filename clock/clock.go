@@ -0,0 +1,38 @@
+// Package clock abstracts time so that timing code can run against either the
+// wall clock (hardware, mock UI) or a manually advanced virtual clock (tests).
+package clock
+
+import "time"
+
+// Clock is the source of time used throughout the firmware.
+// Implemented by Real (wall time) and Virtual (manually advanced time).
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Sleep blocks the calling goroutine for at least d.
+	Sleep(d time.Duration)
+	// AfterFunc calls f in its own goroutine (Real) or from Advance (Virtual)
+	// once d has elapsed.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending AfterFunc call. Stop returns false if the call already fired
+// or was already stopped. *time.Timer satisfies this interface.
+type Timer interface {
+	Stop() bool
+}
+
+// Real is the wall clock, backed by the time package.
+type Real struct{}
+
+func (Real) Now() time.Time        { return time.Now() }
+func (Real) Sleep(d time.Duration) { time.Sleep(d) }
+
+func (Real) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// Since returns the time elapsed since t according to c.
+func Since(c Clock, t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
@@ -0,0 +1,87 @@
+// Tests for the virtual clock
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVirtualAdvance(t *testing.T) {
+	c := NewVirtual(time.Time{})
+	start := c.Now()
+	if start.IsZero() {
+		t.Fatal("Expected non-zero start time")
+	}
+	c.Advance(5 * time.Millisecond)
+	if got := c.Now().Sub(start); got != 5*time.Millisecond {
+		t.Errorf("Expected 5ms elapsed, got %v", got)
+	}
+	if got := Since(c, start); got != 5*time.Millisecond {
+		t.Errorf("Expected Since to report 5ms, got %v", got)
+	}
+}
+
+func TestVirtualAfterFuncOrder(t *testing.T) {
+	c := NewVirtual(time.Time{})
+	start := c.Now()
+	var fired []time.Duration
+	record := func() { fired = append(fired, c.Now().Sub(start)) }
+	c.AfterFunc(3*time.Millisecond, record)
+	c.AfterFunc(1*time.Millisecond, record)
+	stopped := c.AfterFunc(2*time.Millisecond, record)
+	if !stopped.Stop() {
+		t.Error("Expected Stop to succeed on a pending timer")
+	}
+
+	c.Advance(1 * time.Millisecond)
+	if len(fired) != 1 || fired[0] != 1*time.Millisecond {
+		t.Fatalf("Expected one timer at 1ms, got %v", fired)
+	}
+	c.Advance(10 * time.Millisecond)
+	if len(fired) != 2 || fired[1] != 3*time.Millisecond {
+		t.Fatalf("Expected second timer to fire at exactly 3ms, got %v", fired)
+	}
+	if stopped.Stop() {
+		t.Error("Expected Stop to fail on an already stopped timer")
+	}
+}
+
+func TestVirtualTimerChain(t *testing.T) {
+	// A timer that re-arms itself (like a pulse train) fires at every period within one Advance.
+	c := NewVirtual(time.Time{})
+	count := 0
+	var tick func()
+	tick = func() {
+		count++
+		c.AfterFunc(10*time.Millisecond, tick)
+	}
+	c.AfterFunc(10*time.Millisecond, tick)
+	c.Advance(100 * time.Millisecond)
+	if count != 10 {
+		t.Errorf("Expected 10 ticks in 100ms, got %d", count)
+	}
+}
+
+func TestVirtualSleep(t *testing.T) {
+	c := NewVirtual(time.Time{})
+	start := c.Now()
+	done := make(chan time.Time)
+	go func() {
+		c.Sleep(20 * time.Millisecond)
+		done <- c.Now()
+	}()
+	for c.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	c.Advance(19 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("Sleep returned before the clock advanced far enough")
+	case <-time.After(10 * time.Millisecond):
+	}
+	c.Advance(1 * time.Millisecond)
+	woke := <-done
+	if woke.Sub(start) != 20*time.Millisecond {
+		t.Errorf("Expected to wake at 20ms, woke at %v", woke.Sub(start))
+	}
+}
@@ -0,0 +1,156 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Virtual is a Clock that only moves when Advance is called, so tests can step
+// time in exact increments (e.g. 1ms) and assert precisely when things happen.
+//
+// Timers registered with AfterFunc fire synchronously inside Advance, in deadline
+// order, with Now() reporting the timer's deadline while its callback runs.
+// Goroutines blocked in Sleep are released once time has advanced past their wake up time.
+type Virtual struct {
+	mu     sync.Mutex
+	now    time.Time
+	seq    int
+	timers []*virtualTimer
+}
+
+type virtualTimer struct {
+	c     *Virtual
+	at    time.Time
+	seq   int // tie breaker so timers with equal deadlines fire in creation order
+	f     func()
+	fired bool
+}
+
+// NewVirtual returns a virtual clock starting at start.
+// A zero start is replaced with a fixed, arbitrary non-zero time so that
+// code using time.Time{} as "unset" keeps working.
+func NewVirtual(start time.Time) *Virtual {
+	if start.IsZero() {
+		start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return &Virtual{now: start}
+}
+
+func (c *Virtual) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep blocks until another goroutine advances the clock by at least d.
+func (c *Virtual) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	done := make(chan struct{})
+	c.AfterFunc(d, func() { close(done) })
+	<-done
+}
+
+func (c *Virtual) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d < 0 {
+		d = 0
+	}
+	c.seq++
+	t := &virtualTimer{c: c, at: c.now.Add(d), seq: c.seq, f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by d, firing every timer that falls due on the way.
+func (c *Virtual) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+	c.AdvanceTo(end)
+}
+
+// AdvanceTo moves the clock forward to t, firing every timer that falls due on the way.
+// Timers created by callbacks are honoured if they fall due before t.
+func (c *Virtual) AdvanceTo(t time.Time) {
+	for {
+		c.mu.Lock()
+		next := c.nextDueLocked(t)
+		if next == nil {
+			if t.After(c.now) {
+				c.now = t
+			}
+			c.mu.Unlock()
+			return
+		}
+		if next.at.After(c.now) {
+			c.now = next.at
+		}
+		next.fired = true
+		c.removeLocked(next)
+		c.mu.Unlock()
+		next.f()
+	}
+}
+
+// Step advances the clock in increments of step until d has elapsed, which is
+// handy when polling code needs a chance to observe each intermediate time.
+func (c *Virtual) Step(d, step time.Duration, each func(now time.Time)) {
+	for elapsed := time.Duration(0); elapsed < d; elapsed += step {
+		c.Advance(step)
+		if each != nil {
+			each(c.Now())
+		}
+	}
+}
+
+// Pending returns the number of timers (including sleepers) waiting to fire.
+func (c *Virtual) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+func (c *Virtual) nextDueLocked(limit time.Time) *virtualTimer {
+	if len(c.timers) == 0 {
+		return nil
+	}
+	sort.Slice(c.timers, func(i, j int) bool {
+		if c.timers[i].at.Equal(c.timers[j].at) {
+			return c.timers[i].seq < c.timers[j].seq
+		}
+		return c.timers[i].at.Before(c.timers[j].at)
+	})
+	if c.timers[0].at.After(limit) {
+		return nil
+	}
+	return c.timers[0]
+}
+
+func (c *Virtual) removeLocked(t *virtualTimer) {
+	for i, other := range c.timers {
+		if other == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (t *virtualTimer) Stop() bool {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+	if t.fired {
+		return false
+	}
+	for _, other := range t.c.timers {
+		if other == t {
+			t.c.removeLocked(t)
+			t.fired = true
+			return true
+		}
+	}
+	return false
+}
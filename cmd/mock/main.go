@@ -2,6 +2,8 @@
 
 // Run with go run ./cmd/mock
 // Run with go run ./cmd/mock -tea -tinyfont -lotslines
+// Run with go run ./cmd/mock -tea -clock 120
+// Run with go run ./cmd/mock -snapshots /tmp/frames
 
 package main
 
@@ -12,6 +14,7 @@ import (
 	"europi/firmware"
 	"europi/logutil"
 	"europi/mock"
+	"europi/settings"
 	"flag"
 	"strconv"
 	"time"
@@ -20,6 +23,9 @@ import (
 var tea = flag.Bool("tea", false, "use Bubble Tea OLED simulation")
 var tinyFont = flag.Bool("tinyfont", false, "simulate TinyFont mode (21 chars per line)")
 var lotsLines = flag.Bool("lotslines", false, "simulate 4 lines of text (default is 3 lines)")
+var dinClock = flag.Int("clock", 0, "feed a clock of this many BPM into DIN (0 = off)")
+var settingsFile = flag.String("settings", "mock-settings.dat", "file to persist app settings in (empty = in memory only)")
+var snapshotDir = flag.String("snapshots", "", "directory to save a PNG of every pixel frame in (empty = off)")
 
 func main() {
 	flag.Parse()
@@ -42,14 +48,25 @@ func main() {
 
 	var oled display.IOledDevice
 	if *tea {
-		oled = display.NewMockOledDeviceTea(numLines, lineLen)
+		teaOled := display.NewMockOledDeviceTea(numLines, lineLen)
+		teaOled.SnapshotDir = *snapshotDir
+		oled = teaOled
 	} else {
-		oled = display.NewMockOledDevice(numLines, lineLen)
+		mockOled := display.NewMockOledDevice(numLines, lineLen)
+		mockOled.SnapshotDir = *snapshotDir
+		oled = mockOled
 	}
 	if buffered {
 		oled = display.NewBufferedDisplay(oled, numLines)
 	}
 	hw := controls.SetupMockEuroPiWithDisplay(oled)
+	if *settingsFile != "" {
+		store := settings.New(settings.NewFileBackend(*settingsFile))
+		if store.LoadErr != nil {
+			logutil.Println("Settings discarded:", store.LoadErr)
+		}
+		hw.Settings = store
+	}
 	mode := "MOCK "
 	if *tea {
 		mode += "TEA ☕️ "
@@ -67,6 +84,24 @@ func main() {
 	firmware.RegisterApp(apps.HelloWorld{})
 	firmware.RegisterApp(apps.FontDisplay{})
 	firmware.RegisterApp(apps.MenuFun{})
+	// The clock-driven apps the pico has, for -clock to drive
+	firmware.RegisterApp(apps.MultiPulseSync{})
+	firmware.RegisterApp(apps.TriggerGateDelay2{})
+	firmware.RegisterApp(apps.TriggerMirror{})
+	// Pixel apps draw into the mock's framebuffer
+	firmware.RegisterApp(apps.Pixels4{})
+	firmware.RegisterApp(apps.Calibration{})
+
+	// Faster than this the 10ms pulses would have no gap between them
+	const maxClockBPM = int(time.Minute / (20 * time.Millisecond))
+	if *dinClock > maxClockBPM {
+		logutil.Println("Ignoring -clock", *dinClock, "BPM, the most is", maxClockBPM)
+	} else if *dinClock > 0 {
+		period := time.Minute / time.Duration(*dinClock)
+		stop := mock.PlayPulseTrain(hw.DIN, controls.PulseTrain{Period: period, Width: 10 * time.Millisecond})
+		defer stop()
+		logutil.Println("Playing", *dinClock, "BPM clock into DIN")
+	}
 
 	firmware.SplashScreen(hw)
 	logutil.Println("Entering main menu loop. Press B2 to select an app, K2 to scroll.")
@@ -159,6 +194,7 @@ func main() {
 		logutil.Println("Mock input simulation completed.")
 	}()
 	for {
+		hw.Display.SetNumLines(numLines) // An app may have switched layout, e.g. to big digits
 		idx := firmware.MenuChooser(hw, numLines)
 		if idx < 0 {
 			logutil.Println("Exiting main menu loop.")
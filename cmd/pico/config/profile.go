@@ -0,0 +1,5 @@
+package config
+
+import "europi/controls"
+
+var Profile = controls.EuroPiProfile
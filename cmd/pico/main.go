@@ -24,10 +24,10 @@ func main() {
 	var oled display.IOledDevice
 	if config.TinyFont {
 		println("Using TinyFont for OLED display.")
-		oled = display.NewOledDeviceTinyFont(config.NumLines)
+		oled = display.NewOledDeviceTinyFontWithPanel(config.Profile.Panel, config.NumLines)
 	} else {
 		println("Using 8x8 font for OLED display.")
-		oled = display.NewOledDevice8x8(config.NumLines)
+		oled = display.NewOledDevice8x8WithPanel(config.Profile.Panel, config.NumLines)
 	}
 
 	// Wrap with buffered display decorator (optional)
@@ -35,8 +35,8 @@ func main() {
 	// But it DID reduce the amount of calls to backend dev.Display() for the menuchooser when it was coded to call Display() after every K2 knob change. Now its smarter.
 	// oled = display.NewBufferedDisplay(oled, config.NumLines)
 
-	hw := controls.SetupEuroPiWithDisplay(oled)
-	println("EuroPi configured (production mode).")
+	hw := controls.SetupEuroPiWithProfile(config.Profile, oled)
+	println(config.Profile.Name, "configured (production mode).")
 
 	// Register apps
 	firmware.RegisterApp(apps.MultiPulseSync{})
@@ -47,6 +47,7 @@ func main() {
 	firmware.RegisterApp(apps.FontDisplay{})
 	firmware.RegisterApp(apps.MenuFun{})
 	firmware.RegisterApp(apps.Pixels4{})
+	firmware.RegisterApp(apps.Calibration{})
 
 	firmware.SplashScreen(hw)
 	println("Entering main menu loop. Press B2 to select an app, K2 to scroll.")
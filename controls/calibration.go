@@ -0,0 +1,141 @@
+// Per-unit calibration of the analogue input and CV outputs, persisted in Settings
+package controls
+
+import (
+	"europi/settings"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AINCalibration maps raw analogue input ADC readings to volts: MinRaw is the
+// reading at 0V and MaxRaw the reading at 5V (see util.VoltageReader).
+type AINCalibration struct {
+	MinRaw int
+	MaxRaw int
+}
+
+// DefaultAINCalibration is used until the unit has been calibrated.
+var DefaultAINCalibration = AINCalibration{MinRaw: 288, MaxRaw: 22000}
+
+// AINCalibrationFromReadings builds a calibration from the raw reading with 0V
+// applied and the raw reading with refVolts applied.
+func AINCalibrationFromReadings(rawAtZero, rawAtRef int, refVolts float64) AINCalibration {
+	perVolt := float64(rawAtRef-rawAtZero) / refVolts
+	return AINCalibration{
+		MinRaw: rawAtZero,
+		MaxRaw: rawAtZero + int(perVolt*5+0.5),
+	}
+}
+
+// Volts converts a raw reading to volts without the clamping and filtering of
+// util.VoltageReader, so readings above 5V can be measured during calibration.
+func (c AINCalibration) Volts(raw int) float64 {
+	if c.MaxRaw == c.MinRaw {
+		return 0
+	}
+	return float64(raw-c.MinRaw) * 5.0 / float64(c.MaxRaw-c.MinRaw)
+}
+
+// Calibration holds all calibration constants of a unit.
+type Calibration struct {
+	AIN AINCalibration
+	CV  [6]*CVCalibration // nil entries use the linear mapping
+}
+
+const (
+	calAINMinKey = "cal.ain.minraw"
+	calAINMaxKey = "cal.ain.maxraw"
+	calCVKey     = "cal.cv%d"
+)
+
+// LoadCalibration reads the calibration from store, falling back to defaults
+// for anything that has not been calibrated or cannot be parsed.
+func LoadCalibration(store settings.IStore) Calibration {
+	return loadCalibration(store, DefaultAINCalibration)
+}
+
+// LoadProfileCalibration is LoadCalibration with the uncalibrated AIN range
+// taken from profile instead of DefaultAINCalibration.
+func LoadProfileCalibration(store settings.IStore, profile HardwareProfile) Calibration {
+	return loadCalibration(store, profile.AINCalibration())
+}
+
+func loadCalibration(store settings.IStore, ain AINCalibration) Calibration {
+	cal := Calibration{AIN: ain}
+	if store == nil {
+		return cal
+	}
+	minRaw := store.GetInt(calAINMinKey, -1)
+	maxRaw := store.GetInt(calAINMaxKey, -1)
+	if minRaw >= 0 && maxRaw > minRaw {
+		cal.AIN = AINCalibration{MinRaw: minRaw, MaxRaw: maxRaw}
+	}
+	for i := range cal.CV {
+		if v, ok := store.Get(fmt.Sprintf(calCVKey, i+1)); ok {
+			cal.CV[i] = parseCVCalibration(v)
+		}
+	}
+	return cal
+}
+
+// SaveCalibration writes the calibration into store. The caller calls store.Save.
+func SaveCalibration(store settings.IStore, cal Calibration) {
+	store.SetInt(calAINMinKey, cal.AIN.MinRaw)
+	store.SetInt(calAINMaxKey, cal.AIN.MaxRaw)
+	for i, cv := range cal.CV {
+		key := fmt.Sprintf(calCVKey, i+1)
+		if cv == nil {
+			store.Delete(key)
+		} else {
+			store.Set(key, formatCVCalibration(cv))
+		}
+	}
+}
+
+// ApplyCalibration hands the calibration to the analogue input and CV outputs.
+// Inputs and outputs that do not support calibration are left alone.
+func (c *Controls) ApplyCalibration(cal Calibration) {
+	if ain, ok := c.AIN.(interface{ SetCalibration(AINCalibration) }); ok {
+		ain.SetCalibration(cal.AIN)
+	}
+	cvs := []ICV{c.CV1, c.CV2, c.CV3, c.CV4, c.CV5, c.CV6}
+	for i, cv := range cvs {
+		if out, ok := cv.(interface{ SetCalibration(*CVCalibration) }); ok {
+			out.SetCalibration(cal.CV[i])
+		}
+	}
+}
+
+// formatCVCalibration encodes points as "volts:duty,volts:duty,..."
+func formatCVCalibration(c *CVCalibration) string {
+	parts := make([]string, len(c.Points))
+	for i, p := range c.Points {
+		parts[i] = strconv.FormatFloat(p.Volts, 'f', 3, 64) + ":" + strconv.FormatUint(uint64(p.Duty), 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+// parseCVCalibration decodes formatCVCalibration output, returning nil if it is malformed.
+func parseCVCalibration(s string) *CVCalibration {
+	var points []CVCalPoint
+	for _, part := range strings.Split(s, ",") {
+		v, d, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil
+		}
+		volts, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil
+		}
+		duty, err := strconv.ParseUint(d, 10, 32)
+		if err != nil {
+			return nil
+		}
+		points = append(points, CVCalPoint{Volts: volts, Duty: uint32(duty)})
+	}
+	if len(points) < 2 {
+		return nil
+	}
+	return NewCVCalibration(points)
+}
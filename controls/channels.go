@@ -0,0 +1,121 @@
+package controls
+
+import "strings"
+
+// Capability describes what a channel can do, as a bitmask.
+type Capability uint8
+
+const (
+	CapDigital   Capability = 1 << iota // on/off (buttons, DIN, gates on a CV)
+	CapAnalogue                         // a continuous level (knobs, AIN, CV duty)
+	CapVolts                            // the level is calibrated in volts
+	CapInterrupt                        // supports edge handlers
+)
+
+var capabilityNames = []string{"digital", "analogue", "volts", "interrupt"}
+
+func (c Capability) Has(other Capability) bool { return c&other == other }
+
+func (c Capability) String() string {
+	var names []string
+	for i, name := range capabilityNames {
+		if c&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// InputKind says which of the typed fields of an Input is set.
+type InputKind int
+
+const (
+	InputKnob InputKind = iota
+	InputButton
+	InputDigital
+	InputAnalogue
+)
+
+// Input describes one of the EuroPi inputs.
+type Input struct {
+	Name     string
+	Kind     InputKind
+	Caps     Capability
+	Knob     IKnob          // set for InputKnob
+	Button   IButton        // set for InputButton
+	Digital  IDigitalInput  // set for InputDigital
+	Analogue IAnalogueInput // set for InputAnalogue
+}
+
+// Value reads the input as a number: knob position 0..1, 0 or 1 for buttons and
+// DIN, volts for AIN.
+func (in Input) Value() float64 {
+	switch in.Kind {
+	case InputKnob:
+		return in.Knob.Position()
+	case InputButton:
+		return boolValue(in.Button.Pressed())
+	case InputDigital:
+		return boolValue(in.Digital.Get())
+	case InputAnalogue:
+		return in.Analogue.Volts()
+	}
+	return 0
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Output describes one of the CV outputs. Number is 1-based, matching the panel.
+type Output struct {
+	Name   string
+	Number int
+	Caps   Capability
+	CV     ICV
+}
+
+// NumCVs is the number of CV outputs.
+const NumCVs = 6
+
+// CVs returns CV1..CV6 in order, so apps can loop over the outputs.
+func (c *Controls) CVs() []ICV {
+	return []ICV{c.CV1, c.CV2, c.CV3, c.CV4, c.CV5, c.CV6}
+}
+
+// Output returns CV output n (1-based, as printed on the panel), or nil if n is out of range.
+func (c *Controls) Output(n int) ICV {
+	if n < 1 || n > NumCVs {
+		return nil
+	}
+	return c.CVs()[n-1]
+}
+
+// Outputs describes the CV outputs.
+func (c *Controls) Outputs() []Output {
+	outputs := make([]Output, NumCVs)
+	for i, cv := range c.CVs() {
+		outputs[i] = Output{
+			Name:   "CV" + string(rune('1'+i)),
+			Number: i + 1,
+			Caps:   CapDigital | CapAnalogue | CapVolts,
+			CV:     cv,
+		}
+	}
+	return outputs
+}
+
+// Inputs describes the inputs: K1, K2, B1, B2, DIN and AIN.
+func (c *Controls) Inputs() []Input {
+	return []Input{
+		{Name: "K1", Kind: InputKnob, Caps: CapAnalogue, Knob: c.K1},
+		{Name: "K2", Kind: InputKnob, Caps: CapAnalogue, Knob: c.K2},
+		{Name: "B1", Kind: InputButton, Caps: CapDigital | CapInterrupt, Button: c.B1},
+		{Name: "B2", Kind: InputButton, Caps: CapDigital | CapInterrupt, Button: c.B2},
+		{Name: "DIN", Kind: InputDigital, Caps: CapDigital | CapInterrupt, Digital: c.DIN},
+		{Name: "AIN", Kind: InputAnalogue, Caps: CapAnalogue | CapVolts, Analogue: c.AIN},
+	}
+}
@@ -1,7 +1,11 @@
 // Unit tests for IO logic/mocks
 package controls
 
-import "testing"
+import (
+	"europi/clock"
+	"testing"
+	"time"
+)
 
 func TestIO(t *testing.T) {
 	// TODO: Add IO tests
@@ -18,8 +22,7 @@ func TestKnobChoice(t *testing.T) {
 	// Test the Choice method
 	k1, ok := mockControls.K1.(*MockKnob)
 	if ok {
-		// TODO aren't we supposed to be 0..100 range?
-		k1.SetValue(32767) // Set a value for testing
+		k1.SetValue(50) // Knob values are 0..100
 	} else {
 		t.Errorf("K1 is not a MockKnob")
 	}
@@ -27,4 +30,623 @@ func TestKnobChoice(t *testing.T) {
 	if choice != 50 {
 		t.Errorf("Expected 50, got %d", choice)
 	}
+	k1.SetValue(0)
+	if choice := mockControls.K1.Choice([]int{0, 50, 100}); choice != 0 {
+		t.Errorf("Expected 0 at the bottom of the travel, got %d", choice)
+	}
+	k1.SetValue(100)
+	if choice := mockControls.K1.Choice([]int{0, 50, 100}); choice != 100 {
+		t.Errorf("Expected 100 at the top of the travel, got %d", choice)
+	}
+}
+
+func TestKnobPositionAndRange(t *testing.T) {
+	k := &MockKnob{}
+	k.SetPosition(0.25)
+	if k.Value() != 25 {
+		t.Errorf("Expected Value 25, got %d", k.Value())
+	}
+	if r := k.Range(-5, 5); r != -2.5 {
+		t.Errorf("Expected Range(-5, 5) = -2.5, got %v", r)
+	}
+	k.SetValue(250) // out of range values clamp
+	if k.Position() != 1 {
+		t.Errorf("Expected position to clamp to 1, got %v", k.Position())
+	}
+
+	for _, curve := range []Curve{CurveLinear, CurveExponential, CurveLogarithmic} {
+		k.SetPosition(0)
+		if r := k.RangeCurve(20, 2000, curve); r != 20 {
+			t.Errorf("Curve %d: expected 20 at the bottom, got %v", curve, r)
+		}
+		k.SetPosition(1)
+		if r := k.RangeCurve(20, 2000, curve); r < 1999.999 || r > 2000.001 {
+			t.Errorf("Curve %d: expected 2000 at the top, got %v", curve, r)
+		}
+	}
+	k.SetPosition(0.5)
+	exp, log := k.RangeCurve(0, 1, CurveExponential), k.RangeCurve(0, 1, CurveLogarithmic)
+	if !(exp < 0.5 && log > 0.5) {
+		t.Errorf("Expected exponential below and logarithmic above the midpoint, got %v and %v", exp, log)
+	}
+	if back := ApplyCurve(exp, CurveLogarithmic); back < 0.4999 || back > 0.5001 {
+		t.Errorf("Expected logarithmic curve to invert exponential, got %v", back)
+	}
+}
+
+func TestMockDigitalInputFiresEdgeHandlers(t *testing.T) {
+	din := &MockDigitalInput{}
+	rises, falls := 0, 0
+	din.SetEdgeHandlers(func() { rises++ }, func() { falls++ })
+
+	din.SetState(true)
+	din.SetState(true) // no transition, no callback
+	din.SetState(false)
+	if rises != 1 || falls != 1 {
+		t.Errorf("Expected 1 rise and 1 fall, got %d rises and %d falls", rises, falls)
+	}
+
+	din.UnsetInterrupt()
+	din.SetState(true)
+	if rises != 1 {
+		t.Errorf("Expected no callbacks after UnsetInterrupt, got %d rises", rises)
+	}
+}
+
+func TestMockDigitalInputPulseTrain(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	hw := SetupMockEuroPiWithClock(nil, clk)
+	start := clk.Now()
+	var riseTimes, fallTimes []time.Duration
+	hw.DIN.SetEdgeHandlers(
+		func() { riseTimes = append(riseTimes, clk.Now().Sub(start)) },
+		func() { fallTimes = append(fallTimes, clk.Now().Sub(start)) },
+	)
+	din := hw.DIN.(*MockDigitalInput)
+	din.PlayPulseTrain(PulseTrain{Period: 100 * time.Millisecond, Width: 10 * time.Millisecond, Count: 3})
+
+	clk.Advance(5 * time.Millisecond)
+	if !hw.DIN.Get() {
+		t.Error("Expected DIN to be high during the first pulse")
+	}
+	clk.Advance(time.Second)
+	if len(riseTimes) != 3 || len(fallTimes) != 3 {
+		t.Fatalf("Expected 3 pulses, got %d rises and %d falls", len(riseTimes), len(fallTimes))
+	}
+	for i := range riseTimes {
+		wantRise := time.Duration(i) * 100 * time.Millisecond
+		if riseTimes[i] != wantRise || fallTimes[i] != wantRise+10*time.Millisecond {
+			t.Errorf("Pulse %d: expected %v..%v, got %v..%v", i, wantRise, wantRise+10*time.Millisecond, riseTimes[i], fallTimes[i])
+		}
+	}
+}
+
+func TestMockDigitalInputPulseTrainJitterAndStop(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	hw := SetupMockEuroPiWithClock(nil, clk)
+	start := clk.Now()
+	var riseTimes []time.Duration
+	hw.DIN.SetEdgeHandlers(func() { riseTimes = append(riseTimes, clk.Now().Sub(start)) }, nil)
+	stop := hw.DIN.(*MockDigitalInput).PlayPulseTrain(PulseTrain{
+		Period: 100 * time.Millisecond,
+		Width:  10 * time.Millisecond,
+		Jitter: 5 * time.Millisecond,
+		Delay:  50 * time.Millisecond,
+		Seed:   1,
+	})
+	clk.Advance(1 * time.Second)
+	stop()
+	count := len(riseTimes)
+	clk.Advance(1 * time.Second)
+	if len(riseTimes) != count {
+		t.Errorf("Expected no pulses after stop, got %d more", len(riseTimes)-count)
+	}
+	for i, at := range riseTimes {
+		nominal := 50*time.Millisecond + time.Duration(i)*100*time.Millisecond
+		if at < nominal-5*time.Millisecond || at > nominal+5*time.Millisecond {
+			t.Errorf("Pulse %d at %v is outside jitter window around %v", i, at, nominal)
+		}
+	}
+}
+
+func TestMockDigitalInputPulseTrainLimits(t *testing.T) {
+	for _, period := range []time.Duration{0, -time.Second, 1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a period of %v to panic", period)
+				}
+			}()
+			(&MockDigitalInput{clock: clock.NewVirtual(time.Time{})}).PlayPulseTrain(PulseTrain{Period: period})
+		}()
+	}
+
+	// Jitter bigger than the period is cut down so the pulses stay in order
+	clk := clock.NewVirtual(time.Time{})
+	din := &MockDigitalInput{clock: clk}
+	start := clk.Now()
+	var rises, falls []time.Duration
+	din.SetEdgeHandlers(
+		func() { rises = append(rises, clk.Now().Sub(start)) },
+		func() { falls = append(falls, clk.Now().Sub(start)) },
+	)
+	din.PlayPulseTrain(PulseTrain{Period: 10 * time.Millisecond, Width: 4 * time.Millisecond, Jitter: time.Second, Count: 50, Seed: 1})
+	clk.Advance(time.Second)
+	if len(rises) != 50 || len(falls) != 50 {
+		t.Fatalf("Expected 50 pulses, got %d rises and %d falls", len(rises), len(falls))
+	}
+	for i, at := range rises {
+		nominal := time.Duration(i) * 10 * time.Millisecond
+		if at < nominal-3*time.Millisecond || at > nominal+3*time.Millisecond {
+			t.Errorf("Pulse %d at %v is outside the cut down jitter window around %v", i, at, nominal)
+		}
+		if i > 0 && at <= falls[i-1] {
+			t.Errorf("Pulse %d rose at %v, before the previous fall at %v", i, at, falls[i-1])
+		}
+	}
+}
+
+func TestMockCVTimeline(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	hw := SetupMockEuroPiWithClock(nil, clk)
+	cv := hw.CV4.(*MockCV)
+	start := clk.Now()
+
+	// Four 20ms pulses, one every 125ms, then a half level CV
+	for i := 0; i < 4; i++ {
+		hw.CV4.On()
+		clk.Advance(20 * time.Millisecond)
+		hw.CV4.Off()
+		clk.Advance(105 * time.Millisecond)
+	}
+	hw.CV4.Set(MaxDuty / 2)
+	end := clk.Now()
+
+	pulses := cv.Pulses(start, end)
+	if len(pulses) != 4 {
+		t.Fatalf("Expected 4 pulses, got %d", len(pulses))
+	}
+	for i, p := range pulses {
+		if p.Width != 20*time.Millisecond {
+			t.Errorf("Pulse %d: expected width 20ms, got %v", i, p.Width)
+		}
+		if want := start.Add(time.Duration(i) * 125 * time.Millisecond); !p.Start.Equal(want) {
+			t.Errorf("Pulse %d: expected start %v, got %v", i, want, p.Start)
+		}
+	}
+	if n := len(cv.RisingEdges(start, start.Add(250*time.Millisecond))); n != 2 {
+		t.Errorf("Expected 2 rising edges in the first 250ms, got %d", n)
+	}
+	if n := len(cv.Edges(start, end.Add(time.Millisecond))); n != 9 {
+		t.Errorf("Expected 9 edges in total, got %d", n)
+	}
+	if d := cv.DutyAt(start.Add(10 * time.Millisecond)); d != MaxDuty {
+		t.Errorf("Expected duty %d during first pulse, got %d", MaxDuty, d)
+	}
+	if d := cv.DutyAt(end); d != MaxDuty/2 {
+		t.Errorf("Expected duty %d at end, got %d", MaxDuty/2, d)
+	}
+	if h := cv.History(); len(h) != 9 || h[8].Kind != CVSet {
+		t.Errorf("Expected 9 recorded calls ending in Set, got %v", h)
+	}
+	cv.ClearHistory()
+	if len(cv.History()) != 0 {
+		t.Error("Expected empty history after ClearHistory")
+	}
+}
+
+func TestCVCalibration(t *testing.T) {
+	linear := LinearCVCalibration(10000)
+	if d := linear.Duty(5); d != 5000 {
+		t.Errorf("Expected 5V to be duty 5000, got %d", d)
+	}
+	if d := linear.Duty(-1); d != 0 {
+		t.Errorf("Expected negative volts to clamp to duty 0, got %d", d)
+	}
+	if d := linear.Duty(12); d != 10000 {
+		t.Errorf("Expected 12V to clamp to duty 10000, got %d", d)
+	}
+
+	// A unit whose output sags towards the top of its range
+	cal := NewCVCalibration([]CVCalPoint{
+		{Volts: 10, Duty: 9900},
+		{Volts: 0, Duty: 20},
+		{Volts: 5, Duty: 4800},
+	})
+	if d := cal.Duty(0); d != 20 {
+		t.Errorf("Expected 0V at duty 20, got %d", d)
+	}
+	if d := cal.Duty(2.5); d != 2410 {
+		t.Errorf("Expected 2.5V at duty 2410, got %d", d)
+	}
+	if d := cal.Duty(7.5); d != 7350 {
+		t.Errorf("Expected 7.5V at duty 7350, got %d", d)
+	}
+	if v := cal.Volts(7350); v < 7.49 || v > 7.51 {
+		t.Errorf("Expected duty 7350 to read back as 7.5V, got %.3f", v)
+	}
+}
+
+func TestMockCVSetVolts(t *testing.T) {
+	hw := SetupMockEuroPiWithClock(nil, clock.NewVirtual(time.Time{}))
+	cv := hw.CV1.(*MockCV)
+	hw.CV1.SetVolts(1)
+	want := uint32(float64(MaxDuty)/10 + 0.5)
+	if d := cv.DutyAt(hw.Clock.Now()); d != want {
+		t.Errorf("Expected uncalibrated 1V to be duty %d, got %d", want, d)
+	}
+	cv.SetCalibration(NewCVCalibration([]CVCalPoint{{Volts: 0, Duty: 100}, {Volts: 10, Duty: 9100}}))
+	hw.CV1.SetVolts(1)
+	if d := cv.DutyAt(hw.Clock.Now()); d != 1000 {
+		t.Errorf("Expected calibrated 1V to be duty 1000, got %d", d)
+	}
+}
+
+func TestCalibrationPersistence(t *testing.T) {
+	hw := SetupMockEuroPiWithClock(nil, clock.NewVirtual(time.Time{}))
+	if got := hw.AIN.(*MockAnalogueInput).Calibration(); got != DefaultAINCalibration {
+		t.Errorf("Expected default AIN calibration at boot, got %+v", got)
+	}
+
+	ain := AINCalibrationFromReadings(300, 44300, 10) // 4400 raw per volt
+	if ain.MaxRaw != 22300 {
+		t.Errorf("Expected 5V at raw 22300, got %d", ain.MaxRaw)
+	}
+	if v := ain.Volts(44300); v < 9.99 || v > 10.01 {
+		t.Errorf("Expected raw 44300 to read 10V, got %.3f", v)
+	}
+
+	cal := Calibration{AIN: ain}
+	cal.CV[2] = NewCVCalibration([]CVCalPoint{{Volts: 0.02, Duty: 0}, {Volts: 5.1, Duty: 5000}, {Volts: 9.95, Duty: 9999}})
+	SaveCalibration(hw.Settings, cal)
+	if err := hw.Settings.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := LoadCalibration(hw.Settings)
+	if loaded.AIN != ain {
+		t.Errorf("Expected AIN calibration %+v, got %+v", ain, loaded.AIN)
+	}
+	if loaded.CV[0] != nil {
+		t.Error("Expected uncalibrated CV1 to load as nil")
+	}
+	if loaded.CV[2] == nil || len(loaded.CV[2].Points) != 3 || loaded.CV[2].Points[1].Duty != 5000 {
+		t.Fatalf("Expected CV3 calibration to round trip, got %+v", loaded.CV[2])
+	}
+
+	hw.ApplyCalibration(loaded)
+	if got := hw.AIN.(*MockAnalogueInput).Calibration(); got != ain {
+		t.Errorf("Expected AIN calibration to be applied, got %+v", got)
+	}
+	hw.CV3.SetVolts(5.1)
+	if d := hw.CV3.(*MockCV).DutyAt(hw.Clock.Now()); d != 5000 {
+		t.Errorf("Expected calibrated CV3 5.1V at duty 5000, got %d", d)
+	}
+}
+
+func TestOutputScheduler(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	hw := SetupMockEuroPiWithClock(nil, clk)
+	cv1, cv2 := hw.CV1.(*MockCV), hw.CV2.(*MockCV)
+	start := clk.Now()
+
+	// A gate now, a delayed gate, and a gate on another output, all from one call site
+	hw.Scheduler.Trigger(hw.CV1, 10*time.Millisecond)
+	hw.Scheduler.ScheduleGate(hw.CV1, start.Add(50*time.Millisecond), 20*time.Millisecond)
+	hw.Scheduler.ScheduleGate(hw.CV2, start.Add(5*time.Millisecond), 100*time.Millisecond)
+	if !hw.Scheduler.Active(hw.CV1) || cv1.DutyAt(start) != MaxDuty {
+		t.Fatal("Expected CV1 to go high straight away")
+	}
+	clk.Advance(time.Second)
+
+	want := []CVPulse{{Start: start, Width: 10 * time.Millisecond, Duty: MaxDuty}, {Start: start.Add(50 * time.Millisecond), Width: 20 * time.Millisecond, Duty: MaxDuty}}
+	if got := cv1.Pulses(start, clk.Now()); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Expected CV1 pulses %v, got %v", want, got)
+	}
+	if got := cv2.PulseWidths(start, clk.Now()); len(got) != 1 || got[0] != 100*time.Millisecond {
+		t.Errorf("Expected one 100ms pulse on CV2, got %v", got)
+	}
+	if hw.Scheduler.Active(hw.CV1) || hw.Scheduler.Active(hw.CV2) || clk.Pending() != 0 {
+		t.Error("Expected the scheduler to be idle once all gates ended")
+	}
+}
+
+func TestOutputSchedulerOverlapAndCancel(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	hw := SetupMockEuroPiWithClock(nil, clk)
+	cv := hw.CV3.(*MockCV)
+	start := clk.Now()
+
+	// Overlapping gates merge into one long gate
+	hw.Scheduler.Trigger(hw.CV3, 30*time.Millisecond)
+	clk.Advance(20 * time.Millisecond)
+	hw.Scheduler.Trigger(hw.CV3, 30*time.Millisecond)
+	clk.Advance(100 * time.Millisecond)
+	if got := cv.PulseWidths(start, clk.Now()); len(got) != 1 || got[0] != 50*time.Millisecond {
+		t.Errorf("Expected one merged 50ms pulse, got %v", got)
+	}
+
+	// Cancel switches the output off and drops the pending gate
+	cv.ClearHistory()
+	from := clk.Now()
+	hw.Scheduler.Trigger(hw.CV3, 30*time.Millisecond)
+	hw.Scheduler.ScheduleGate(hw.CV3, from.Add(50*time.Millisecond), 10*time.Millisecond)
+	clk.Advance(10 * time.Millisecond)
+	hw.Scheduler.Cancel(hw.CV3)
+	clk.Advance(100 * time.Millisecond)
+	if got := cv.PulseWidths(from, clk.Now()); len(got) != 1 || got[0] != 10*time.Millisecond {
+		t.Errorf("Expected the gate to be cut short at 10ms and nothing after, got %v", got)
+	}
+}
+
+// manualClock's timers only fire when the test calls them, so a timer can be
+// made to fire late, after it has been replaced.
+type manualClock struct {
+	now    time.Time
+	timers []*manualTimer
+}
+
+type manualTimer struct {
+	f       func()
+	stopped bool
+}
+
+func (t *manualTimer) Stop() bool {
+	was := !t.stopped
+	t.stopped = true
+	return was
+}
+
+func (c *manualClock) Now() time.Time        { return c.now }
+func (c *manualClock) Sleep(d time.Duration) { c.now = c.now.Add(d) }
+func (c *manualClock) AfterFunc(d time.Duration, f func()) clock.Timer {
+	t := &manualTimer{f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// armed counts the timers that haven't been stopped.
+func (c *manualClock) armed() int {
+	n := 0
+	for _, t := range c.timers {
+		if !t.stopped {
+			n++
+		}
+	}
+	return n
+}
+
+func TestOutputSchedulerIgnoresReplacedTimer(t *testing.T) {
+	clk := &manualClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	hw := SetupMockEuroPiWithClock(nil, clk)
+	hw.Scheduler.ScheduleGate(hw.CV1, clk.now.Add(10*time.Millisecond), 5*time.Millisecond)
+	fired := clk.timers[0]
+	// An earlier gate replaces the timer just as the old one fires
+	hw.Scheduler.ScheduleGate(hw.CV2, clk.now.Add(5*time.Millisecond), 5*time.Millisecond)
+	fired.f()
+	if n := clk.armed(); n != 1 {
+		t.Errorf("Expected the late callback to leave just the current timer armed, got %d", n)
+	}
+
+	// The current timer still fires the gates
+	for i := 0; i < 4 && clk.armed() > 0; i++ {
+		next := clk.timers[len(clk.timers)-1]
+		clk.now = clk.now.Add(5 * time.Millisecond)
+		next.stopped = true
+		next.f()
+	}
+	if hw.Scheduler.Active(hw.CV1) || hw.Scheduler.Active(hw.CV2) || clk.armed() != 0 {
+		t.Error("Expected both gates to have run, leaving no timers")
+	}
+}
+
+func TestIndexedChannels(t *testing.T) {
+	hw := SetupMockEuroPiWithDisplay(nil)
+	if hw.Output(3) != hw.CV3 || hw.Output(0) != nil || hw.Output(7) != nil {
+		t.Error("Output(n) should address CV1..CV6 by panel number")
+	}
+	for i, out := range hw.Outputs() {
+		if out.Number != i+1 || out.CV != hw.CVs()[i] || out.Name != "CV"+string(rune('1'+i)) {
+			t.Errorf("Unexpected output %d: %+v", i, out)
+		}
+		out.CV.Set(uint32(i))
+	}
+	if h := hw.CV6.(*MockCV).History(); len(h) != 1 || h[0].Duty != 5 {
+		t.Error("Expected CVs() to be in panel order")
+	}
+
+	hw.K2.(*MockKnob).SetValue(100)
+	hw.B1.(*MockButton).SetPressed(true)
+	hw.AIN.(*MockAnalogueInput).SetVolts(2.5)
+	values := map[string]float64{}
+	for _, in := range hw.Inputs() {
+		values[in.Name] = in.Value()
+	}
+	want := map[string]float64{"K1": 0, "K2": 1, "B1": 1, "B2": 0, "DIN": 0, "AIN": 2.5}
+	for name, v := range want {
+		if values[name] != v {
+			t.Errorf("Expected %s to read %v, got %v", name, v, values[name])
+		}
+	}
+	if caps := hw.Inputs()[4].Caps; !caps.Has(CapInterrupt) || caps.String() != "digital|interrupt" {
+		t.Errorf("Unexpected DIN capabilities %v", caps)
+	}
+}
+
+func TestProfileCalibration(t *testing.T) {
+	profile := EuroPiProfile
+	profile.AIN.MinRaw, profile.AIN.MaxRaw = 100, 30000
+	want := AINCalibration{MinRaw: 100, MaxRaw: 30000}
+	if got := LoadProfileCalibration(nil, profile).AIN; got != want {
+		t.Errorf("Expected the profile's AIN range %+v, got %+v", want, got)
+	}
+	if DefaultAINCalibration != EuroPiProfile.AINCalibration() {
+		t.Errorf("Expected DefaultAINCalibration to be left alone, got %+v", DefaultAINCalibration)
+	}
+
+	// A calibrated unit still uses its stored calibration
+	hw := SetupMockEuroPiWithClock(nil, clock.NewVirtual(time.Time{}))
+	stored := AINCalibration{MinRaw: 250, MaxRaw: 21000}
+	SaveCalibration(hw.Settings, Calibration{AIN: stored})
+	if got := LoadProfileCalibration(hw.Settings, profile).AIN; got != stored {
+		t.Errorf("Expected the stored AIN calibration %+v, got %+v", stored, got)
+	}
+}
+
+func TestHardwareProfiles(t *testing.T) {
+	if err := EuroPiProfile.Validate(); err != nil {
+		t.Errorf("%s: %v", EuroPiProfile.Name, err)
+	}
+	if EuroPiProfile.Panel.Height != 32 {
+		t.Error("Expected a 128x32 panel on the EuroPi")
+	}
+
+	// The mock is wired like the original EuroPi, with one PWM output per CV
+	hw := SetupMockEuroPiWithDisplay(nil)
+	if hw.Profile.Name != "EuroPi" || len(hw.Profile.CV) != len(hw.CVs()) {
+		t.Errorf("Unexpected mock profile %+v", hw.Profile)
+	}
+
+	broken := map[string]func(p *HardwareProfile){
+		"pin used twice":        func(p *HardwareProfile) { p.B2 = p.B1 },
+		"pin clashes with CV":   func(p *HardwareProfile) { p.DIN = p.CV[0].GPIO },
+		"no such pin":           func(p *HardwareProfile) { p.B1 = 30 },
+		"knob without ADC":      func(p *HardwareProfile) { p.K1.GPIO = 2 },
+		"empty ADC range":       func(p *HardwareProfile) { p.AIN.MaxRaw = p.AIN.MinRaw },
+		"wrong PWM slice":       func(p *HardwareProfile) { p.CV[2].Slice = 3 },
+		"wrong PWM channel":     func(p *HardwareProfile) { p.CV[5].Channel = 0 },
+		"SDA on the wrong bus":  func(p *HardwareProfile) { p.Panel.I2CBus = 1 },
+		"unsupported height":    func(p *HardwareProfile) { p.Panel.Height = 48 },
+		"no PWM frequency":      func(p *HardwareProfile) { p.PWMFrequency = 0 },
+		"bad display address":   func(p *HardwareProfile) { p.Panel.Address = 0x50 },
+		"display pin clashes":   func(p *HardwareProfile) { p.B1 = p.Panel.SCL },
+		"I2C1 pins on I2C0 bus": func(p *HardwareProfile) { p.Panel.SDA, p.Panel.SCL = 2, 3 },
+	}
+	for name, breakIt := range broken {
+		p := EuroPiProfile
+		breakIt(&p)
+		if err := p.Validate(); err == nil {
+			t.Errorf("Expected %q to fail validation", name)
+		}
+	}
+}
+
+func TestCVSlew(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	hw := SetupMockEuroPiWithClock(nil, clk)
+	cv := hw.CV1.(*MockCV)
+	start := clk.Now()
+
+	// Linear: a full scale rise takes Rise, falls are instant
+	hw.CV1.SetSlew(SlewConfig{Mode: SlewLinear, Rise: 100 * time.Millisecond})
+	hw.CV1.Set(MaxDuty)
+	clk.Advance(50 * time.Millisecond)
+	if d := cv.DutyAt(clk.Now()); d < MaxDuty/2-MaxDuty/50 || d > MaxDuty/2+MaxDuty/50 {
+		t.Errorf("Expected about half scale halfway through the rise, got %d", d)
+	}
+	clk.Advance(50 * time.Millisecond)
+	if d := cv.DutyAt(clk.Now()); d != MaxDuty {
+		t.Errorf("Expected full scale after the rise time, got %d", d)
+	}
+	if clk.Pending() != 0 {
+		t.Error("Expected the slew timer to stop once the target was reached")
+	}
+	hw.CV1.Set(0)
+	if d := cv.DutyAt(clk.Now()); d != 0 {
+		t.Errorf("Expected an instant fall, got %d", d)
+	}
+
+	// Exponential: about 63% of the way after one time constant
+	hw.CV1.SetSlew(SlewConfig{Mode: SlewExponential, Rise: 20 * time.Millisecond, Fall: 20 * time.Millisecond})
+	hw.CV1.Set(1000)
+	clk.Advance(20 * time.Millisecond)
+	if d := cv.DutyAt(clk.Now()); d < 600 || d > 660 {
+		t.Errorf("Expected about 632 after one time constant, got %d", d)
+	}
+
+	// Gates bypass the slew and cancel it
+	hw.CV1.On()
+	clk.Advance(time.Second)
+	if d := cv.DutyAt(clk.Now()); d != MaxDuty {
+		t.Errorf("Expected On to stay on, got %d", d)
+	}
+	hw.CV1.Off()
+	if d := cv.DutyAt(clk.Now()); d != 0 || clk.Pending() != 0 {
+		t.Errorf("Expected Off to be instant, got %d", d)
+	}
+
+	// The slew steps are in the history at the fixed update rate
+	if rises := cv.Edges(start, start.Add(2*SlewInterval)); len(rises) != 1 || rises[0].At != start.Add(SlewInterval) {
+		t.Errorf("Expected the first slew step after %v, got %v", SlewInterval, rises)
+	}
+}
+
+func TestCVSlewSharesOneTick(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	hw := SetupMockEuroPiWithClock(nil, clk)
+	cvs := []ICV{hw.CV1, hw.CV2, hw.CV3, hw.CV4, hw.CV5, hw.CV6}
+	for i, cv := range cvs {
+		cv.SetSlew(SlewConfig{Mode: SlewLinear, Rise: time.Duration(i+1) * 10 * time.Millisecond})
+		cv.Set(MaxDuty)
+	}
+	if clk.Pending() != 1 {
+		t.Fatalf("Expected one timer for all the slewing outputs, got %d", clk.Pending())
+	}
+
+	// CV1 arrives first, the rest keep going on the same tick
+	clk.Advance(10 * time.Millisecond)
+	if hw.CV1.Duty() != MaxDuty || hw.CV2.Duty() >= MaxDuty || clk.Pending() != 1 {
+		t.Errorf("Expected CV1 done and CV2 still moving on one timer, got %d, %d and %d timers", hw.CV1.Duty(), hw.CV2.Duty(), clk.Pending())
+	}
+	hw.CV2.Off()
+	clk.Advance(50 * time.Millisecond)
+	for i, cv := range cvs[2:] {
+		if cv.Duty() != MaxDuty {
+			t.Errorf("Expected CV%d to reach full scale, got %d", i+3, cv.Duty())
+		}
+	}
+	if hw.CV2.Duty() != 0 {
+		t.Errorf("Expected CV2 to stay off after its slew was cancelled, got %d", hw.CV2.Duty())
+	}
+	if clk.Pending() != 0 {
+		t.Error("Expected the tick to stop once nothing is slewing")
+	}
+}
+
+func TestCVReadableState(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	hw := SetupMockEuroPiWithClock(nil, clk)
+	cv := hw.CV2
+	if cv.IsHigh() || cv.Duty() != 0 || !cv.LastChange().IsZero() {
+		t.Fatal("Expected a fresh output to be off and never changed")
+	}
+
+	clk.Advance(10 * time.Millisecond)
+	cv.On()
+	if !cv.IsHigh() || cv.Duty() != MaxDuty || cv.LastChange() != clk.Now() {
+		t.Errorf("Expected On to be readable, got duty %d changed at %v", cv.Duty(), cv.LastChange())
+	}
+	changed := clk.Now()
+	clk.Advance(10 * time.Millisecond)
+	cv.On() // no change
+	if cv.LastChange() != changed {
+		t.Error("Expected writing the same level not to count as a change")
+	}
+
+	cv.SetVolts(5)
+	if v := cv.Volts(); v < 4.99 || v > 5.01 {
+		t.Errorf("Expected 5V, got %v", v)
+	}
+
+	// The state follows the slew, not the target
+	cv.SetSlew(SlewConfig{Mode: SlewLinear, Fall: 100 * time.Millisecond})
+	cv.Set(0)
+	clk.Advance(10 * time.Millisecond)
+	if !cv.IsHigh() || cv.Duty() >= MaxDuty/2 {
+		t.Errorf("Expected the output to be part way down the slew, got %d", cv.Duty())
+	}
+	clk.Advance(100 * time.Millisecond)
+	if cv.IsHigh() {
+		t.Error("Expected the output to be off once the slew finished")
+	}
 }
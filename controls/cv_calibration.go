@@ -0,0 +1,90 @@
+// Volt calibration for CV outputs, shared by real and mock CVs
+package controls
+
+import "sort"
+
+// CV outputs produce 0..10V
+const (
+	MinCVVolts = 0.0
+	MaxCVVolts = 10.0
+)
+
+// CVCalPoint maps an output voltage to the PWM duty that produces it.
+type CVCalPoint struct {
+	Volts float64
+	Duty  uint32
+}
+
+// CVCalibration converts volts to PWM duty for one CV output by linear
+// interpolation between measured points. Points must be sorted by Volts.
+type CVCalibration struct {
+	Points []CVCalPoint
+}
+
+// LinearCVCalibration is the uncalibrated mapping, 0V at duty 0 and 10V at maxDuty.
+func LinearCVCalibration(maxDuty uint32) *CVCalibration {
+	return &CVCalibration{Points: []CVCalPoint{
+		{Volts: MinCVVolts, Duty: 0},
+		{Volts: MaxCVVolts, Duty: maxDuty},
+	}}
+}
+
+// NewCVCalibration builds a calibration from measured points, sorting them by voltage.
+func NewCVCalibration(points []CVCalPoint) *CVCalibration {
+	sorted := append([]CVCalPoint(nil), points...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Volts < sorted[j].Volts })
+	return &CVCalibration{Points: sorted}
+}
+
+// Duty returns the PWM duty for volts, clamped to 0..10V and to the calibrated range.
+func (c *CVCalibration) Duty(volts float64) uint32 {
+	if c == nil || len(c.Points) == 0 {
+		return LinearCVCalibration(MaxDuty).Duty(volts)
+	}
+	volts = clampVolts(volts)
+	pts := c.Points
+	if volts <= pts[0].Volts {
+		return pts[0].Duty
+	}
+	for i := 1; i < len(pts); i++ {
+		if volts <= pts[i].Volts {
+			return uint32(interpolate(volts, pts[i-1].Volts, pts[i].Volts, float64(pts[i-1].Duty), float64(pts[i].Duty)) + 0.5)
+		}
+	}
+	return pts[len(pts)-1].Duty
+}
+
+// Volts returns the voltage produced by duty, the inverse of Duty.
+func (c *CVCalibration) Volts(duty uint32) float64 {
+	if c == nil || len(c.Points) == 0 {
+		return LinearCVCalibration(MaxDuty).Volts(duty)
+	}
+	pts := c.Points
+	if duty <= pts[0].Duty {
+		return pts[0].Volts
+	}
+	for i := 1; i < len(pts); i++ {
+		if duty <= pts[i].Duty {
+			return interpolate(float64(duty), float64(pts[i-1].Duty), float64(pts[i].Duty), pts[i-1].Volts, pts[i].Volts)
+		}
+	}
+	return pts[len(pts)-1].Volts
+}
+
+func clampVolts(v float64) float64 {
+	if v < MinCVVolts {
+		return MinCVVolts
+	}
+	if v > MaxCVVolts {
+		return MaxCVVolts
+	}
+	return v
+}
+
+// interpolate maps x from [x0, x1] onto [y0, y1].
+func interpolate(x, x0, x1, y0, y1 float64) float64 {
+	if x1 == x0 {
+		return y0
+	}
+	return y0 + (x-x0)*(y1-y0)/(x1-x0)
+}
@@ -1,18 +1,35 @@
 // IO struct and interfaces (IKnob, IButton, etc.)
 package controls
 
-import "europi/display"
+import (
+	"europi/clock"
+	"europi/display"
+	"europi/settings"
+	"time"
+)
 
 // IKnob interface
 // Returns the current value of the knob
-// Implemented by both real and mock knobs
+// Implemented by both real and mock knobs, with identical semantics
 type IKnob interface {
+	// Value returns the knob position as 0..100
 	Value() int
+	// Position returns the knob position as 0.0..1.0 at full resolution
+	Position() float64
+	// Range maps the knob position linearly onto min..max
+	Range(min, max float64) float64
+	// RangeCurve maps the knob position onto min..max through a response curve
+	RangeCurve(min, max float64, curve Curve) float64
+	// Choice returns a value from the list chosen by the current knob position
 	Choice(values []int) int
 }
 
 type IButton interface {
 	Pressed() bool
+	// SetEdgeHandlers registers callbacks fired when the button goes down and
+	// comes back up. On hardware they run in the pin interrupt, so keep them short.
+	SetEdgeHandlers(pressCallback func(), releaseCallback func())
+	UnsetInterrupt() // Unset the interrupt handler
 }
 
 type IDigitalInput interface {
@@ -24,14 +41,34 @@ type IDigitalInput interface {
 type IAnalogueInput interface {
 	Volts() float64
 	Value() int
+	// Raw returns the unfiltered ADC reading (0..65535), used for calibration
+	Raw() int
 }
 
 type ICV interface {
 	Set(value uint32)
+	// SetVolts sets the output to volts (0..10V) using the output's calibration
+	SetVolts(volts float64)
+	// On and Off write a gate, bypassing any slew
 	On()
 	Off()
+	// SetSlew sets the glide applied to Set and SetVolts (the zero SlewConfig for none)
+	SetSlew(config SlewConfig)
+
+	// Duty returns the duty currently on the output, including any slew in progress
+	Duty() uint32
+	// Volts returns the current output voltage according to the output's calibration
+	Volts() float64
+	// IsHigh reports whether the output is above 0V, e.g. a gate that is on
+	IsHigh() bool
+	// LastChange returns when the output level last changed
+	LastChange() time.Time
 }
 
+// MaxDuty is the PWM duty that drives a CV output fully on. The hardware
+// updates it from the PWM top value in ConfigureCV.
+var MaxDuty uint32 = 9999
+
 // Controls struct holds all hardware/mocked Controls for EuroPi
 // This is used by both production and mock entry points
 type Controls struct {
@@ -43,4 +80,13 @@ type Controls struct {
 	// Outputs
 	CV1, CV2, CV3, CV4, CV5, CV6 ICV
 	Display                      display.IOledDevice
+	// Clock is the time source for all timing logic (real clock on hardware,
+	// optionally a clock.Virtual in tests)
+	Clock clock.Clock
+	// Settings persists app settings across exits and power cycles
+	Settings settings.IStore
+	// Scheduler switches the CV outputs on and off at scheduled times
+	Scheduler *OutputScheduler
+	// Profile describes the pins and display of the hardware (EuroPiProfile for the mock)
+	Profile HardwareProfile
 }
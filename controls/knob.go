@@ -0,0 +1,65 @@
+// Knob position mapping shared by real and mock knobs
+package controls
+
+import "math"
+
+// KnobResolution is the number of steps the real knob position is resolved to
+// (Value still reports 0..100).
+const KnobResolution = 1000
+
+// Curve shapes how a knob position maps onto a range.
+type Curve int
+
+const (
+	// CurveLinear maps the position straight onto the range.
+	CurveLinear Curve = iota
+	// CurveExponential gives fine control at the bottom of the range, e.g. for times and frequencies.
+	CurveExponential
+	// CurveLogarithmic gives fine control at the top of the range.
+	CurveLogarithmic
+)
+
+// curveSteepness controls how strongly the exponential/log curves bend.
+const curveSteepness = 4.0
+
+// ApplyCurve reshapes a 0..1 position. The exponential and logarithmic curves
+// are inverses of each other and both keep 0 and 1 fixed.
+func ApplyCurve(pos float64, curve Curve) float64 {
+	pos = clampUnit(pos)
+	switch curve {
+	case CurveExponential:
+		return (math.Exp(curveSteepness*pos) - 1) / (math.Exp(curveSteepness) - 1)
+	case CurveLogarithmic:
+		return math.Log(1+pos*(math.Exp(curveSteepness)-1)) / curveSteepness
+	default:
+		return pos
+	}
+}
+
+// knobRange maps a 0..1 position onto min..max through curve.
+func knobRange(pos, min, max float64, curve Curve) float64 {
+	return min + ApplyCurve(pos, curve)*(max-min)
+}
+
+// knobChoice picks the value at the position, dividing the knob travel into
+// equal segments, one per value.
+func knobChoice(pos float64, values []int) int {
+	if len(values) == 0 {
+		return 0
+	}
+	idx := int(clampUnit(pos) * float64(len(values)))
+	if idx >= len(values) {
+		idx = len(values) - 1
+	}
+	return values[idx]
+}
+
+func clampUnit(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	if x > 1 {
+		return 1
+	}
+	return x
+}
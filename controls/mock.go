@@ -3,56 +3,130 @@
 // Mock implementations (pure Go, no hardware deps)
 package controls
 
-import "europi/display"
+import (
+	"europi/clock"
+	"europi/display"
+	"europi/settings"
+	"sync"
+)
 
 // MockKnob implements IKnob
-// SetValue allows test code to set the value
+// SetValue (0..100) or SetPosition (0.0..1.0) allows test code to set the position
 // Value returns the current value
 
-type MockKnob struct{ val int }
+type MockKnob struct{ pos float64 }
 
-func (m *MockKnob) Value() int     { return m.val }
-func (m *MockKnob) SetValue(v int) { m.val = v }
+func (m *MockKnob) Value() int        { return int(m.pos*100 + 0.5) }
+func (m *MockKnob) Position() float64 { return m.pos }
+func (m *MockKnob) SetValue(v int)    { m.pos = clampUnit(float64(v) / 100) }
+func (m *MockKnob) SetPosition(p float64) {
+	m.pos = clampUnit(p)
+}
+
+func (m *MockKnob) Range(min, max float64) float64 {
+	return knobRange(m.pos, min, max, CurveLinear)
+}
+
+func (m *MockKnob) RangeCurve(min, max float64, curve Curve) float64 {
+	return knobRange(m.pos, min, max, curve)
+}
 
-// Choice returns a value from the list chosen by the current mock knob value
+// Choice returns a value from the list chosen by the current mock knob position
 func (m *MockKnob) Choice(values []int) int {
-	if len(values) == 0 {
-		return 0
-	}
-	percent := float64(m.Value()) / 65535.0
-	if percent >= 1.0 {
-		return values[len(values)-1]
-	}
-	idx := int(percent * float64(len(values)))
-	if idx >= len(values) {
-		idx = len(values) - 1
-	}
-	return values[idx]
+	return knobChoice(m.pos, values)
 }
 
 // MockButton implements IButton
-// SetPressed allows test code to set the pressed state
+// SetPressed allows test code to set the pressed state, firing the press/release
+// callbacks on transitions just like the hardware interrupt does.
+
+type MockButton struct {
+	mu              sync.Mutex
+	pressed         bool
+	pressCallback   func()
+	releaseCallback func()
+}
 
-type MockButton struct{ pressed bool }
+func (m *MockButton) Pressed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pressed
+}
+
+func (m *MockButton) SetPressed(p bool) {
+	m.mu.Lock()
+	changed := p != m.pressed
+	m.pressed = p
+	press, release := m.pressCallback, m.releaseCallback
+	m.mu.Unlock()
 
-func (m *MockButton) Pressed() bool     { return m.pressed }
-func (m *MockButton) SetPressed(p bool) { m.pressed = p }
+	if !changed {
+		return
+	}
+	if p && press != nil {
+		press()
+	} else if !p && release != nil {
+		release()
+	}
+}
+
+func (m *MockButton) SetEdgeHandlers(pressCallback func(), releaseCallback func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pressCallback = pressCallback
+	m.releaseCallback = releaseCallback
+}
+
+func (m *MockButton) UnsetInterrupt() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pressCallback = nil
+	m.releaseCallback = nil
+}
 
 // MockDigitalInput implements IDigitalInput
-// SetState allows test code to set the state
+// SetState allows test code to set the state, firing the rise/fall callbacks on
+// transitions just like the hardware interrupt does. PlayPulseTrain (see
+// mock_pulses.go) schedules a whole clock signal on the input.
 
-type MockDigitalInput struct{ 
-	state bool 
+type MockDigitalInput struct {
+	mu           sync.Mutex
+	state        bool
 	riseCallback func()
 	fallCallback func()
+	clock        clock.Clock // used to schedule pulse trains
 }
 
-func (m *MockDigitalInput) Get() bool       { return m.state }
-func (m *MockDigitalInput) SetState(s bool) { m.state = s }
+func (m *MockDigitalInput) Get() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state
+}
+
+// SetState sets the input level. A change of level fires the matching edge callback.
+// Callbacks are invoked without holding the lock, so they may call Get.
+func (m *MockDigitalInput) SetState(s bool) {
+	m.mu.Lock()
+	changed := s != m.state
+	m.state = s
+	rise, fall := m.riseCallback, m.fallCallback
+	m.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if s && rise != nil {
+		rise()
+	} else if !s && fall != nil {
+		fall()
+	}
+}
 
 func (d *MockDigitalInput) SetEdgeHandlers(riseCallback func(), fallCallback func()) {
 	// This is a mock, so we don't actually set any hardware interrupts.
-	// Instead, we just provide a way to set the callbacks if needed.
+	// SetState invokes these callbacks on level changes instead.
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	d.riseCallback = riseCallback
 	d.fallCallback = fallCallback
 }
@@ -60,6 +134,8 @@ func (d *MockDigitalInput) SetEdgeHandlers(riseCallback func(), fallCallback fun
 func (d *MockDigitalInput) UnsetInterrupt() {
 	// This is a mock, so we don't actually unset any hardware interrupts.
 	// Just reset the callbacks to nil.
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	d.riseCallback = nil
 	d.fallCallback = nil
 }
@@ -68,41 +144,99 @@ func (d *MockDigitalInput) UnsetInterrupt() {
 // SetVolts/SetValue allow test code to set the values
 
 type MockAnalogueInput struct {
-	volts float64
-	value int
+	volts       float64
+	value       int
+	raw         int
+	calibration AINCalibration
 }
 
 func (m *MockAnalogueInput) Volts() float64     { return m.volts }
 func (m *MockAnalogueInput) Value() int         { return m.value }
+func (m *MockAnalogueInput) Raw() int           { return m.raw }
 func (m *MockAnalogueInput) SetVolts(v float64) { m.volts = v }
 func (m *MockAnalogueInput) SetValue(val int)   { m.value = val }
+func (m *MockAnalogueInput) SetRaw(raw int)     { m.raw = raw }
+
+// SetCalibration records the calibration applied at boot
+func (m *MockAnalogueInput) SetCalibration(cal AINCalibration) { m.calibration = cal }
+func (m *MockAnalogueInput) Calibration() AINCalibration       { return m.calibration }
 
 // MockCV implements ICV
+// Every Set/On/Off call is recorded with a timestamp from the clock so tests can
+// inspect what an app did on its outputs (see mock_timeline.go for queries).
 
 type MockCV struct {
-	val     uint32
-	on, off bool
+	outputState
+	mu          sync.Mutex
+	clock       clock.Clock
+	history     []CVEvent
+	calibration *CVCalibration // nil means linear 0..10V
+	slews       *slewDriver    // the scheduler's slew tick, or one of its own if nil
+	slew        *slew          // created on first use, steps on the mock's clock
 }
 
-func (m *MockCV) Set(v uint32) { m.val = v }
-func (m *MockCV) On()          { m.on = true }
-func (m *MockCV) Off()         { m.off = true }
+func (m *MockCV) Set(v uint32)           { m.slewStage().set(v) }
+func (m *MockCV) SetVolts(volts float64) { m.Set(m.calibration.Duty(volts)) }
+
+func (m *MockCV) On() {
+	m.slewStage().bypass(MaxDuty)
+	m.record(CVOn, MaxDuty)
+}
 
-// SetupEuroPiWithDisplay returns a Controls struct with all fields set to mocks, using the provided display
+func (m *MockCV) Off() {
+	m.slewStage().bypass(0)
+	m.record(CVOff, 0)
+}
+
+// SetSlew sets the slew applied to Set. The slew steps are recorded in the
+// history like any other Set, at the times the clock says they happened.
+func (m *MockCV) SetSlew(config SlewConfig) { m.slewStage().configure(config) }
+
+func (m *MockCV) slewStage() *slew {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.slew == nil {
+		if m.slews == nil {
+			m.slews = newSlewDriver(m.clock)
+		}
+		m.slew = newSlew(m.slews, func(duty uint32) { m.record(CVSet, duty) })
+	}
+	return m.slew
+}
+
+// SetCalibration sets the volts to duty mapping used by SetVolts (nil for linear)
+func (m *MockCV) SetCalibration(cal *CVCalibration) { m.calibration = cal }
+
+func (m *MockCV) Volts() float64 { return m.calibration.Volts(m.Duty()) }
+
+// SetupEuroPiWithDisplay returns a Controls struct with all fields set to mocks, using the provided display.
+// The mocks run on the wall clock; tests can swap in a clock.Virtual via SetupMockEuroPiWithClock.
 func SetupMockEuroPiWithDisplay(display display.IOledDevice) *Controls {
-	return &Controls{
-		K1:      &MockKnob{},
-		K2:      &MockKnob{},
-		B1:      &MockButton{},
-		B2:      &MockButton{},
-		DIN:     &MockDigitalInput{},
-		AIN:     &MockAnalogueInput{},
-		CV1:     &MockCV{},
-		CV2:     &MockCV{},
-		CV3:     &MockCV{},
-		CV4:     &MockCV{},
-		CV5:     &MockCV{},
-		CV6:     &MockCV{},
-		Display: display,
+	return SetupMockEuroPiWithClock(display, clock.Real{})
+}
+
+// SetupMockEuroPiWithClock returns a Controls struct with all fields set to mocks, using the provided display and clock
+func SetupMockEuroPiWithClock(display display.IOledDevice, clk clock.Clock) *Controls {
+	scheduler := NewOutputScheduler(clk)
+	hw := &Controls{
+		K1:        &MockKnob{},
+		K2:        &MockKnob{},
+		B1:        &MockButton{},
+		B2:        &MockButton{},
+		DIN:       &MockDigitalInput{clock: clk},
+		AIN:       &MockAnalogueInput{},
+		CV1:       &MockCV{clock: clk, slews: scheduler.slews},
+		CV2:       &MockCV{clock: clk, slews: scheduler.slews},
+		CV3:       &MockCV{clock: clk, slews: scheduler.slews},
+		CV4:       &MockCV{clock: clk, slews: scheduler.slews},
+		CV5:       &MockCV{clock: clk, slews: scheduler.slews},
+		CV6:       &MockCV{clock: clk, slews: scheduler.slews},
+		Display:   display,
+		Clock:     clk,
+		Settings:  settings.New(settings.NewMemoryBackend()),
+		Scheduler: scheduler,
+		Profile:   EuroPiProfile,
 	}
+	hw.ApplyCalibration(LoadProfileCalibration(hw.Settings, hw.Profile))
+	return hw
 }
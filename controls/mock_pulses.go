@@ -0,0 +1,112 @@
+//go:build !tinygo
+
+// Scheduled pulse trains for the mock digital input
+package controls
+
+import (
+	"europi/clock"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// PulseTrain describes a clock signal to play into a MockDigitalInput.
+type PulseTrain struct {
+	Period time.Duration // time between rising edges
+	Width  time.Duration // how long each pulse stays high
+	Jitter time.Duration // each rising edge is moved by a random amount in [-Jitter, +Jitter], less than half the gap between pulses
+	Count  int           // number of pulses to play, 0 plays until stopped
+	Delay  time.Duration // time before the first rising edge
+	Seed   int64         // seed for the jitter so runs are repeatable
+}
+
+// PlayPulseTrain schedules train on the input using the input's clock.
+// Edges fire the registered edge handlers exactly like SetState does.
+// With a clock.Virtual the edges happen as the test advances time; with the real
+// clock they happen in the background. Call the returned func to stop the train early.
+// It panics if the period is too short for a pulse and a gap, which would
+// otherwise schedule edges with no delay forever.
+func (m *MockDigitalInput) PlayPulseTrain(train PulseTrain) (stop func()) {
+	if train.Period < 2 {
+		panic(fmt.Sprintf("controls: PulseTrain period %v is too short for a pulse", train.Period))
+	}
+	clk := m.clock
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	width := train.Width
+	if width <= 0 || width >= train.Period {
+		width = train.Period / 2 // keep a gap between pulses
+	}
+	// Jitter of up to half the gap keeps every rise after the previous fall
+	if maxJitter := (train.Period - width) / 2; train.Jitter > maxJitter {
+		train.Jitter = maxJitter
+	}
+	rng := rand.New(rand.NewSource(train.Seed))
+	start := clk.Now().Add(train.Delay)
+
+	var mu sync.Mutex
+	var timer clock.Timer
+	stopped := false
+	pulse := 0
+
+	var scheduleRise func()
+	scheduleRise = func() {
+		if train.Count > 0 && pulse >= train.Count {
+			return
+		}
+		at := start.Add(time.Duration(pulse) * train.Period)
+		if train.Jitter > 0 {
+			at = at.Add(time.Duration(rng.Int63n(int64(2*train.Jitter)+1)) - train.Jitter)
+		}
+		delay := at.Sub(clk.Now())
+		if delay < 0 {
+			delay = 0
+		}
+		timer = clk.AfterFunc(delay, func() {
+			mu.Lock()
+			if stopped {
+				mu.Unlock()
+				return
+			}
+			mu.Unlock()
+			m.SetState(true)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if stopped {
+				return
+			}
+			timer = clk.AfterFunc(width, func() {
+				mu.Lock()
+				if stopped {
+					mu.Unlock()
+					return
+				}
+				mu.Unlock()
+				m.SetState(false)
+
+				mu.Lock()
+				defer mu.Unlock()
+				pulse++
+				if !stopped {
+					scheduleRise()
+				}
+			})
+		})
+	}
+
+	mu.Lock()
+	scheduleRise()
+	mu.Unlock()
+
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = true
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
@@ -0,0 +1,150 @@
+//go:build !tinygo
+
+// Recorded output timeline for MockCV
+package controls
+
+import (
+	"europi/clock"
+	"time"
+)
+
+// CVEventKind identifies which ICV method produced a CVEvent.
+type CVEventKind int
+
+const (
+	CVSet CVEventKind = iota
+	CVOn
+	CVOff
+)
+
+func (k CVEventKind) String() string {
+	switch k {
+	case CVOn:
+		return "On"
+	case CVOff:
+		return "Off"
+	default:
+		return "Set"
+	}
+}
+
+// CVEvent is one recorded call on a MockCV. Duty is the resulting PWM duty
+// (MaxDuty for On, 0 for Off).
+type CVEvent struct {
+	At   time.Time
+	Kind CVEventKind
+	Duty uint32
+}
+
+// CVEdge is a change of a MockCV between low (duty 0) and high (duty > 0).
+type CVEdge struct {
+	At     time.Time
+	Rising bool
+}
+
+// CVPulse is a completed high period on a MockCV.
+type CVPulse struct {
+	Start time.Time
+	Width time.Duration
+	Duty  uint32 // duty at the start of the pulse
+}
+
+func (m *MockCV) record(kind CVEventKind, duty uint32) {
+	clk := m.clock
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	now := clk.Now()
+	m.update(duty, now)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.history = append(m.history, CVEvent{At: now, Kind: kind, Duty: duty})
+}
+
+// History returns a copy of every recorded call, oldest first.
+func (m *MockCV) History() []CVEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]CVEvent(nil), m.history...)
+}
+
+// ClearHistory forgets all recorded calls. The current duty is kept.
+func (m *MockCV) ClearHistory() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.history = nil
+}
+
+// DutyAt returns the duty the output had at time t (0 before the first call).
+func (m *MockCV) DutyAt(t time.Time) uint32 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	duty := uint32(0)
+	for _, ev := range m.history {
+		if ev.At.After(t) {
+			break
+		}
+		duty = ev.Duty
+	}
+	return duty
+}
+
+// Edges returns the low/high transitions that happened in [from, to).
+// Repeated On calls while already high do not count as edges.
+func (m *MockCV) Edges(from, to time.Time) []CVEdge {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var edges []CVEdge
+	high := false
+	for _, ev := range m.history {
+		nowHigh := ev.Duty > 0
+		if nowHigh != high && !ev.At.Before(from) && ev.At.Before(to) {
+			edges = append(edges, CVEdge{At: ev.At, Rising: nowHigh})
+		}
+		high = nowHigh
+	}
+	return edges
+}
+
+// RisingEdges returns the times the output went high in [from, to).
+func (m *MockCV) RisingEdges(from, to time.Time) []time.Time {
+	var times []time.Time
+	for _, e := range m.Edges(from, to) {
+		if e.Rising {
+			times = append(times, e.At)
+		}
+	}
+	return times
+}
+
+// Pulses returns the completed high periods that started in [from, to).
+// A pulse that is still high at the end of the history is not included.
+func (m *MockCV) Pulses(from, to time.Time) []CVPulse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var pulses []CVPulse
+	var current *CVPulse
+	for _, ev := range m.history {
+		high := ev.Duty > 0
+		switch {
+		case high && current == nil:
+			current = &CVPulse{Start: ev.At, Duty: ev.Duty}
+		case !high && current != nil:
+			current.Width = ev.At.Sub(current.Start)
+			if !current.Start.Before(from) && current.Start.Before(to) {
+				pulses = append(pulses, *current)
+			}
+			current = nil
+		}
+	}
+	return pulses
+}
+
+// PulseWidths returns the widths of the pulses that started in [from, to).
+func (m *MockCV) PulseWidths(from, to time.Time) []time.Duration {
+	var widths []time.Duration
+	for _, p := range m.Pulses(from, to) {
+		widths = append(widths, p.Width)
+	}
+	return widths
+}
@@ -0,0 +1,44 @@
+package controls
+
+import (
+	"sync"
+	"time"
+)
+
+// outputState is the readable side of a CV output, embedded by CV and MockCV
+// so apps and tests can ask an output what it is doing instead of keeping
+// their own copy.
+type outputState struct {
+	stateMu   sync.Mutex
+	duty      uint32
+	changedAt time.Time
+}
+
+// update records the duty written to the hardware at time at.
+func (o *outputState) update(duty uint32, at time.Time) {
+	o.stateMu.Lock()
+	defer o.stateMu.Unlock()
+	if duty != o.duty {
+		o.duty = duty
+		o.changedAt = at
+	}
+}
+
+// Duty returns the duty currently on the output, including any slew in progress.
+func (o *outputState) Duty() uint32 {
+	o.stateMu.Lock()
+	defer o.stateMu.Unlock()
+	return o.duty
+}
+
+// IsHigh reports whether the output is above 0V, e.g. a gate that is on.
+func (o *outputState) IsHigh() bool {
+	return o.Duty() > 0
+}
+
+// LastChange returns when the duty last changed (the zero time if it never has).
+func (o *outputState) LastChange() time.Time {
+	o.stateMu.Lock()
+	defer o.stateMu.Unlock()
+	return o.changedAt
+}
@@ -0,0 +1,117 @@
+package controls
+
+import (
+	"europi/display"
+	"fmt"
+)
+
+// HardwareProfile describes how a EuroPi variant is wired: which RP2040 pins
+// the controls are on, which PWM slice and channel drives each CV output, the
+// raw ADC ranges and the display. SetupEuroPiWithProfile builds the Controls
+// from it, so supporting another variant is a matter of adding a profile.
+type HardwareProfile struct {
+	Name         string
+	B1, B2       int // button GPIOs
+	DIN          int // digital input GPIO
+	K1, K2       ADCInput
+	AIN          ADCInput // MinRaw/MaxRaw are the readings at 0V and 5V until calibrated
+	CV           [NumCVs]PWMOutput
+	PWMFrequency uint32 // Hz
+	Panel        display.PanelConfig
+}
+
+// ADCInput is an analogue input pin and the raw readings at the ends of its range.
+type ADCInput struct {
+	GPIO   int
+	MinRaw int
+	MaxRaw int
+}
+
+// PWMOutput is the pin, PWM slice and channel driving a CV output.
+type PWMOutput struct {
+	GPIO    int
+	Slice   int
+	Channel uint8
+}
+
+// EuroPiProfile is the original EuroPi.
+var EuroPiProfile = HardwareProfile{
+	Name: "EuroPi",
+	B1:   4,
+	B2:   5,
+	DIN:  22,
+	K1:   ADCInput{GPIO: 27, MinRaw: 0, MaxRaw: 65535},
+	K2:   ADCInput{GPIO: 28, MinRaw: 0, MaxRaw: 65535},
+	AIN:  ADCInput{GPIO: 26, MinRaw: DefaultAINCalibration.MinRaw, MaxRaw: DefaultAINCalibration.MaxRaw},
+	CV: [NumCVs]PWMOutput{
+		{GPIO: 21, Slice: 2, Channel: 1},
+		{GPIO: 20, Slice: 2, Channel: 0},
+		{GPIO: 16, Slice: 0, Channel: 0},
+		{GPIO: 17, Slice: 0, Channel: 1},
+		{GPIO: 18, Slice: 1, Channel: 0},
+		{GPIO: 19, Slice: 1, Channel: 1},
+	},
+	PWMFrequency: 20000,
+	Panel:        display.EuroPiPanel,
+}
+
+// AINCalibration is the profile's uncalibrated AIN range, used until the unit
+// has been calibrated.
+func (p HardwareProfile) AINCalibration() AINCalibration {
+	return AINCalibration{MinRaw: p.AIN.MinRaw, MaxRaw: p.AIN.MaxRaw}
+}
+
+// Validate checks the profile for pins that don't exist or are used twice, ADC
+// inputs on pins without an ADC, and PWM slices/channels that don't match their pin.
+func (p HardwareProfile) Validate() error {
+	used := map[int]string{}
+	use := func(gpio int, name string) error {
+		if gpio < 0 || gpio > 29 {
+			return fmt.Errorf("%s: no GPIO%d", name, gpio)
+		}
+		if other, ok := used[gpio]; ok {
+			return fmt.Errorf("%s: GPIO%d is already used by %s", name, gpio, other)
+		}
+		used[gpio] = name
+		return nil
+	}
+
+	for _, pin := range []struct {
+		gpio int
+		name string
+	}{{p.B1, "B1"}, {p.B2, "B2"}, {p.DIN, "DIN"}, {p.Panel.SDA, "display SDA"}, {p.Panel.SCL, "display SCL"}} {
+		if err := use(pin.gpio, pin.name); err != nil {
+			return err
+		}
+	}
+	for _, in := range []struct {
+		adc  ADCInput
+		name string
+	}{{p.K1, "K1"}, {p.K2, "K2"}, {p.AIN, "AIN"}} {
+		if err := use(in.adc.GPIO, in.name); err != nil {
+			return err
+		}
+		// The RP2040 ADC inputs are GPIO26..29
+		if in.adc.GPIO < 26 {
+			return fmt.Errorf("%s: GPIO%d has no ADC", in.name, in.adc.GPIO)
+		}
+		if in.adc.MinRaw < 0 || in.adc.MaxRaw > 65535 || in.adc.MinRaw >= in.adc.MaxRaw {
+			return fmt.Errorf("%s: bad raw range %d..%d", in.name, in.adc.MinRaw, in.adc.MaxRaw)
+		}
+	}
+	for i, out := range p.CV {
+		name := fmt.Sprintf("CV%d", i+1)
+		if err := use(out.GPIO, name); err != nil {
+			return err
+		}
+		// Each pair of pins shares a PWM slice, the even pin on channel A (0)
+		if out.Slice != (out.GPIO/2)%8 || int(out.Channel) != out.GPIO%2 {
+			return fmt.Errorf("%s: GPIO%d is PWM slice %d channel %d, not slice %d channel %d",
+				name, out.GPIO, (out.GPIO/2)%8, out.GPIO%2, out.Slice, out.Channel)
+		}
+	}
+	if p.PWMFrequency == 0 {
+		return fmt.Errorf("PWM frequency not set")
+	}
+	return p.Panel.Validate()
+}
@@ -4,7 +4,9 @@
 package controls
 
 import (
+	"europi/clock"
 	"europi/display"
+	"europi/settings"
 	"europi/util"
 	"machine"
 )
@@ -92,39 +94,61 @@ func (b *Button) Pressed() bool {
 	return b.input.Get()
 }
 
+// SetEdgeHandlers fires pressCallback/releaseCallback from the pin interrupt.
+// The input is inverted, so a press is a logical rising edge.
+func (b *Button) SetEdgeHandlers(pressCallback func(), releaseCallback func()) {
+	b.input.SetEdgeHandlers(pressCallback, releaseCallback)
+}
+
+func (b *Button) UnsetInterrupt() {
+	b.input.UnsetInterrupt()
+}
+
 // Knob abstraction
 type Knob struct {
 	adc  machine.ADC
 	proc *util.SmartKnobProcessor
 }
 
-func NewKnob(adcPin machine.Pin) *Knob {
+func NewKnob(adcPin machine.Pin, clk clock.Clock) *Knob {
 	adc := machine.ADC{Pin: adcPin}
 	adc.Configure(machine.ADCConfig{})
+	proc := util.NewSmartKnobProcessor(clk)
+	proc.Resolution = KnobResolution
+	proc.ResumeThreshold = 2 * KnobResolution / 100 // same feel as 2 steps of 0..100
 	return &Knob{
 		adc:  adc,
-		proc: util.NewSmartKnobProcessor(),
+		proc: proc,
 	}
 }
 
+// SetRawRange sets the raw ADC readings at the ends of the knob's travel
+func (k *Knob) SetRawRange(min, max int) {
+	k.proc.RawMin = min
+	k.proc.RawMax = max
+}
+
+// Position returns the knob position as 0.0..1.0
+func (k *Knob) Position() float64 {
+	return float64(k.proc.Process(int(k.adc.Get()))) / float64(k.proc.Resolution)
+}
+
+// Value returns the knob position as 0..100
 func (k *Knob) Value() int {
-	return k.proc.Process(int(k.adc.Get()))
+	return int(k.Position()*100 + 0.5)
+}
+
+func (k *Knob) Range(min, max float64) float64 {
+	return knobRange(k.Position(), min, max, CurveLinear)
+}
+
+func (k *Knob) RangeCurve(min, max float64, curve Curve) float64 {
+	return knobRange(k.Position(), min, max, curve)
 }
 
 // Choice returns a value from the list chosen by the current knob position
 func (k *Knob) Choice(values []int) int {
-	if len(values) == 0 {
-		return 0
-	}
-	percent := float64(k.Value()) / 65535.0
-	if percent >= 1.0 {
-		return values[len(values)-1]
-	}
-	idx := int(percent * float64(len(values)))
-	if idx >= len(values) {
-		idx = len(values) - 1
-	}
-	return values[idx]
+	return knobChoice(k.Position(), values)
 }
 
 // AnalogueInput abstraction
@@ -136,10 +160,15 @@ type AnalogueInput struct {
 func NewAnalogueInput(adcPin machine.Pin) *AnalogueInput {
 	adc := machine.ADC{Pin: adcPin}
 	adc.Configure(machine.ADCConfig{})
-	return &AnalogueInput{
-		adc:    adc,
-		reader: util.NewVoltageReader(288, 22000, 16, 3),
-	}
+	a := &AnalogueInput{adc: adc}
+	a.SetCalibration(DefaultAINCalibration)
+	return a
+}
+
+// SetCalibration replaces the raw to volts mapping, e.g. with the values
+// measured by the calibration app
+func (a *AnalogueInput) SetCalibration(cal AINCalibration) {
+	a.reader = util.NewVoltageReader(cal.MinRaw, cal.MaxRaw, 16, 3)
 }
 
 func (a *AnalogueInput) Volts() float64 {
@@ -150,79 +179,156 @@ func (a *AnalogueInput) Value() int {
 	return int(a.Volts() * 100)
 }
 
+func (a *AnalogueInput) Raw() int {
+	return int(a.adc.Get())
+}
+
 // CV output abstraction
 type CV struct {
-	Index int
+	outputState
+	Index       int
+	Calibration *CVCalibration // volts to duty mapping for SetVolts, nil means linear 0..10V
+	slew        *slew
+	clock       clock.Clock
 }
 
-var MaxDuty uint32 = 9999
+func newCV(index int, slews *slewDriver) *CV {
+	c := &CV{Index: index, clock: slews.clock}
+	c.slew = newSlew(slews, c.write)
+	return c
+}
+
+// write sets the PWM duty and records it as the output's state
+func (c *CV) write(duty uint32) {
+	SetCV(c.Index, duty)
+	c.update(duty, c.clock.Now())
+}
+
+// pwmSlice is the part of the machine PWM group API used for the CV outputs
+type pwmSlice interface {
+	Configure(config machine.PWMConfig) error
+	Set(channel uint8, value uint32)
+	Top() uint32
+}
+
+var pwmSlices = [...]pwmSlice{
+	machine.PWM0, machine.PWM1, machine.PWM2, machine.PWM3,
+	machine.PWM4, machine.PWM5, machine.PWM6, machine.PWM7,
+}
+
+// cvOutputs maps CV1..CV6 to their PWM slice and channel, set by ConfigureCV
+var cvOutputs = EuroPiProfile.CV
 
 func SetCV(cv int, value uint32) {
-	switch cv {
-	case 1:
-		machine.PWM2.Set(1, value)
-	case 2:
-		machine.PWM2.Set(0, value)
-	case 3:
-		machine.PWM0.Set(0, value)
-	case 4:
-		machine.PWM0.Set(1, value)
-	case 5:
-		machine.PWM1.Set(0, value)
-	case 6:
-		machine.PWM1.Set(1, value)
+	if cv < 1 || cv > NumCVs {
+		return
 	}
+	out := cvOutputs[cv-1]
+	pwmSlices[out.Slice].Set(out.Channel, value)
 }
 
 func (c *CV) Set(value uint32) {
-	SetCV(c.Index, value)
+	c.slew.set(value)
+}
+
+// SetVolts sets the output voltage (0..10V) through the output's calibration table
+func (c *CV) SetVolts(volts float64) {
+	c.Set(c.Calibration.Duty(volts))
+}
+
+// SetSlew sets the slew applied to Set and SetVolts
+func (c *CV) SetSlew(config SlewConfig) {
+	c.slew.configure(config)
+}
+
+// SetCalibration sets the volts to duty mapping used by SetVolts (nil for linear)
+func (c *CV) SetCalibration(cal *CVCalibration) {
+	c.Calibration = cal
 }
 
 func (c *CV) On() {
-	SetCV(c.Index, MaxDuty)
+	c.slew.bypass(MaxDuty)
+	c.write(MaxDuty)
 }
 
 func (c *CV) Off() {
-	SetCV(c.Index, 0)
+	c.slew.bypass(0)
+	c.write(0)
 }
 
-func ConfigureCV() {
-	const pwmFrequency = 20000
-	const pwmPeriod = 1e9 / pwmFrequency
-
-	machine.PWM0.Configure(machine.PWMConfig{Period: pwmPeriod})
-	machine.PWM1.Configure(machine.PWMConfig{Period: pwmPeriod})
-	machine.PWM2.Configure(machine.PWMConfig{Period: pwmPeriod})
+func (c *CV) Volts() float64 {
+	return c.Calibration.Volts(c.Duty())
+}
 
-	machine.GPIO21.Configure(machine.PinConfig{Mode: machine.PinPWM})
-	machine.GPIO20.Configure(machine.PinConfig{Mode: machine.PinPWM})
-	machine.GPIO16.Configure(machine.PinConfig{Mode: machine.PinPWM})
-	machine.GPIO17.Configure(machine.PinConfig{Mode: machine.PinPWM})
-	machine.GPIO18.Configure(machine.PinConfig{Mode: machine.PinPWM})
-	machine.GPIO19.Configure(machine.PinConfig{Mode: machine.PinPWM})
+// ConfigureCV sets up the PWM slices and pins of the profile's CV outputs
+func ConfigureCV(profile HardwareProfile) {
+	pwmPeriod := uint64(1e9 / profile.PWMFrequency)
+
+	configured := map[int]bool{}
+	for _, out := range profile.CV {
+		if !configured[out.Slice] {
+			pwmSlices[out.Slice].Configure(machine.PWMConfig{Period: pwmPeriod})
+			configured[out.Slice] = true
+		}
+		machine.Pin(out.GPIO).Configure(machine.PinConfig{Mode: machine.PinPWM})
+	}
+	cvOutputs = profile.CV
 
-	if MaxDuty != machine.PWM0.Top() {
-		MaxDuty = machine.PWM0.Top()
+	// All slices share the same period, so any of them gives the top value
+	if top := pwmSlices[profile.CV[0].Slice].Top(); MaxDuty != top {
+		MaxDuty = top
 	}
 }
 
 // Initializes all real hardware IO
 func SetupEuroPiWithDisplay(display display.IOledDevice) *Controls {
+	return SetupEuroPiWithProfile(EuroPiProfile, display)
+}
+
+// SetupEuroPiWithProfile initializes the hardware IO of the EuroPi variant described by profile
+func SetupEuroPiWithProfile(profile HardwareProfile, display display.IOledDevice) *Controls {
+	if err := profile.Validate(); err != nil {
+		panic("invalid hardware profile: " + err.Error())
+	}
 	machine.InitADC()
-	ConfigureCV()
-	return &Controls{
-		K1:      NewKnob(machine.ADC1),
-		K2:      NewKnob(machine.ADC2),
-		B1:      NewButton(machine.GPIO4),
-		B2:      NewButton(machine.GPIO5),
-		DIN:     NewDigitalInput(machine.GPIO22, true),
-		AIN:     NewAnalogueInput(machine.ADC0),
-		CV1:     &CV{Index: 1},
-		CV2:     &CV{Index: 2},
-		CV3:     &CV{Index: 3},
-		CV4:     &CV{Index: 4},
-		CV5:     &CV{Index: 5},
-		CV6:     &CV{Index: 6},
-		Display: display,
+	ConfigureCV(profile)
+	clk := clock.Real{}
+	store := settings.New(settings.NewFlashBackend())
+	if store.LoadErr != nil {
+		println("Settings discarded:", store.LoadErr.Error())
 	}
+	scheduler := NewOutputScheduler(clk)
+	hw := &Controls{
+		K1:        newProfileKnob(profile.K1, clk),
+		K2:        newProfileKnob(profile.K2, clk),
+		B1:        NewButton(machine.Pin(profile.B1)),
+		B2:        NewButton(machine.Pin(profile.B2)),
+		DIN:       NewDigitalInput(machine.Pin(profile.DIN), true),
+		AIN:       newProfileAnalogueInput(profile),
+		CV1:       newCV(1, scheduler.slews),
+		CV2:       newCV(2, scheduler.slews),
+		CV3:       newCV(3, scheduler.slews),
+		CV4:       newCV(4, scheduler.slews),
+		CV5:       newCV(5, scheduler.slews),
+		CV6:       newCV(6, scheduler.slews),
+		Display:   display,
+		Clock:     clk,
+		Settings:  store,
+		Scheduler: scheduler,
+		Profile:   profile,
+	}
+	hw.ApplyCalibration(LoadProfileCalibration(store, profile))
+	return hw
+}
+
+func newProfileKnob(in ADCInput, clk clock.Clock) *Knob {
+	k := NewKnob(machine.Pin(in.GPIO), clk)
+	k.SetRawRange(in.MinRaw, in.MaxRaw)
+	return k
+}
+
+func newProfileAnalogueInput(profile HardwareProfile) *AnalogueInput {
+	a := NewAnalogueInput(machine.Pin(profile.AIN.GPIO))
+	a.SetCalibration(profile.AINCalibration())
+	return a
 }
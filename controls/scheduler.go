@@ -0,0 +1,172 @@
+package controls
+
+import (
+	"europi/clock"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OutputScheduler switches CV outputs on and off at scheduled times, so apps
+// don't need a busy main loop checking whether a gate is due to end. A single
+// clock timer is armed for the earliest pending event; when it fires every due
+// event is applied and the timer is re-armed for the next one.
+//
+// Overlapping gates on one output merge: the output goes high with the first
+// gate and stays high until the last of them ends.
+//
+// It also owns the tick that steps the slewing CV outputs (see slewDriver).
+type OutputScheduler struct {
+	clock clock.Clock
+	slews *slewDriver
+
+	mu       sync.Mutex
+	events   []scheduledEvent // sorted by at, then seq
+	seq      uint64
+	open     map[ICV]int // number of gates currently holding each output high
+	timer    clock.Timer
+	timerAt  time.Time
+	timerGen uint64 // identifies the current timer, so one that fired as it was replaced is ignored
+}
+
+type scheduledEvent struct {
+	cv  ICV
+	at  time.Time
+	on  bool
+	seq uint64
+}
+
+func NewOutputScheduler(clk clock.Clock) *OutputScheduler {
+	return &OutputScheduler{clock: clk, slews: newSlewDriver(clk), open: map[ICV]int{}}
+}
+
+// ScheduleGate turns cv on at time at and off again width later. A time in the
+// past fires straight away.
+func (s *OutputScheduler) ScheduleGate(cv ICV, at time.Time, width time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.insertLocked(scheduledEvent{cv: cv, at: at, on: true})
+	s.insertLocked(scheduledEvent{cv: cv, at: at.Add(width), on: false})
+	s.fireLocked()
+}
+
+// Trigger turns cv on now for width.
+func (s *OutputScheduler) Trigger(cv ICV, width time.Duration) {
+	s.ScheduleGate(cv, s.clock.Now(), width)
+}
+
+// Cancel drops the pending events for cv and turns it off if a gate is holding it high.
+func (s *OutputScheduler) Cancel(cv ICV) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancelLocked(cv)
+	s.armLocked()
+}
+
+// CancelAll cancels every output, e.g. when an app exits.
+func (s *OutputScheduler) CancelAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.events) > 0 {
+		s.cancelLocked(s.events[0].cv)
+	}
+	for cv := range s.open {
+		s.cancelLocked(cv)
+	}
+	s.stopTimerLocked()
+}
+
+// Active reports whether cv is being held high by a gate or has a gate pending.
+func (s *OutputScheduler) Active(cv ICV) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.open[cv] > 0 {
+		return true
+	}
+	for _, ev := range s.events {
+		if ev.cv == cv {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *OutputScheduler) insertLocked(ev scheduledEvent) {
+	s.seq++
+	ev.seq = s.seq
+	i := sort.Search(len(s.events), func(i int) bool { return s.events[i].at.After(ev.at) })
+	s.events = append(s.events, scheduledEvent{})
+	copy(s.events[i+1:], s.events[i:])
+	s.events[i] = ev
+}
+
+func (s *OutputScheduler) cancelLocked(cv ICV) {
+	kept := s.events[:0]
+	for _, ev := range s.events {
+		if ev.cv != cv {
+			kept = append(kept, ev)
+		}
+	}
+	s.events = kept
+	if s.open[cv] > 0 {
+		cv.Off()
+	}
+	delete(s.open, cv)
+}
+
+// fireLocked applies every event that is due and re-arms the timer.
+func (s *OutputScheduler) fireLocked() {
+	now := s.clock.Now()
+	for len(s.events) > 0 && !s.events[0].at.After(now) {
+		ev := s.events[0]
+		s.events = s.events[1:]
+		if ev.on {
+			if s.open[ev.cv] == 0 {
+				ev.cv.On()
+			}
+			s.open[ev.cv]++
+		} else if s.open[ev.cv] > 0 {
+			s.open[ev.cv]--
+			if s.open[ev.cv] == 0 {
+				ev.cv.Off()
+			}
+		}
+	}
+	s.armLocked()
+}
+
+// armLocked makes sure the timer is set for the earliest pending event.
+func (s *OutputScheduler) armLocked() {
+	if len(s.events) == 0 {
+		s.stopTimerLocked()
+		return
+	}
+	next := s.events[0].at
+	if s.timer != nil && s.timerAt.Equal(next) {
+		return
+	}
+	s.stopTimerLocked()
+	s.timerAt = next
+	gen := s.timerGen
+	s.timer = s.clock.AfterFunc(next.Sub(s.clock.Now()), func() { s.onTimer(gen) })
+}
+
+// stopTimerLocked stops the timer. Its callback may already be waiting for
+// the lock, so the generation moves on for onTimer to see it's stale.
+func (s *OutputScheduler) stopTimerLocked() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.timerGen++
+}
+
+func (s *OutputScheduler) onTimer(gen uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if gen != s.timerGen {
+		return // replaced or stopped after it fired, the current timer is still armed
+	}
+	s.timer = nil
+	s.fireLocked()
+}
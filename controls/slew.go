@@ -0,0 +1,219 @@
+package controls
+
+import (
+	"europi/clock"
+	"math"
+	"sync"
+	"time"
+)
+
+// SlewMode is the shape of the glide between two CV values.
+type SlewMode int
+
+const (
+	// SlewLinear moves at a constant rate: Rise/Fall is the time for a full scale change.
+	SlewLinear SlewMode = iota
+	// SlewExponential approaches the target like an RC filter: Rise/Fall is the time constant.
+	SlewExponential
+)
+
+// SlewConfig sets the slew (portamento) of a CV output. A zero Rise or Fall
+// means changes in that direction are instant; the zero SlewConfig turns slew off.
+// Gates written with On/Off always bypass the slew.
+type SlewConfig struct {
+	Mode SlewMode
+	Rise time.Duration
+	Fall time.Duration
+}
+
+// SlewInterval is how often a slewing output is updated.
+const SlewInterval = time.Millisecond
+
+// slew is the per-output stage between Set and the hardware write. While the
+// output is moving it is on its slewDriver, which steps it towards the target
+// every SlewInterval.
+type slew struct {
+	mu      sync.Mutex
+	config  SlewConfig
+	driver  *slewDriver
+	write   func(duty uint32)
+	current float64
+	target  float64
+	written uint32
+	last    time.Time
+	moving  bool
+}
+
+func newSlew(driver *slewDriver, write func(duty uint32)) *slew {
+	return &slew{driver: driver, write: write}
+}
+
+func (s *slew) configure(config SlewConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+}
+
+// set slews towards duty, or writes it straight away if there's no slew in that direction.
+func (s *slew) set(duty uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.target = float64(duty)
+	if s.timeFor(s.target) == 0 {
+		s.jumpLocked(duty)
+		return
+	}
+	if !s.moving {
+		s.moving = true
+		s.last = s.driver.clock.Now()
+		s.driver.add(s)
+	}
+}
+
+// bypass cancels any slew in progress because the caller has written duty
+// itself, e.g. a gate from On/Off.
+func (s *slew) bypass(duty uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopLocked(duty)
+	s.written = duty
+}
+
+func (s *slew) jumpLocked(duty uint32) {
+	s.stopLocked(duty)
+	s.writeLocked(duty)
+}
+
+func (s *slew) stopLocked(duty uint32) {
+	if s.moving {
+		s.moving = false
+		s.driver.remove(s)
+	}
+	s.current, s.target = float64(duty), float64(duty)
+}
+
+func (s *slew) writeLocked(duty uint32) {
+	s.written = duty
+	s.write(duty)
+}
+
+// timeFor returns the rise or fall time for moving from the current value to target.
+func (s *slew) timeFor(target float64) time.Duration {
+	if target > s.current {
+		return s.config.Rise
+	}
+	return s.config.Fall
+}
+
+// step moves the output towards the target for the time since the last step,
+// and takes it off the driver once it gets there.
+func (s *slew) step(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.moving {
+		return // cancelled by bypass or an instant set since the tick started
+	}
+	dt := now.Sub(s.last)
+	s.last = now
+
+	t := s.timeFor(s.target)
+	diff := s.target - s.current
+	switch {
+	case t == 0:
+		s.current = s.target
+	case s.config.Mode == SlewExponential:
+		s.current += diff * (1 - math.Exp(-float64(dt)/float64(t)))
+		if math.Abs(s.target-s.current) < 0.5 {
+			s.current = s.target
+		}
+	default:
+		maxStep := float64(MaxDuty) * float64(dt) / float64(t)
+		if math.Abs(diff)-maxStep < 0.5 {
+			s.current = s.target
+		} else {
+			s.current += math.Copysign(maxStep, diff)
+		}
+	}
+
+	if duty := uint32(s.current + 0.5); duty != s.written {
+		s.writeLocked(duty)
+	}
+	if s.current == s.target {
+		s.moving = false
+		s.driver.remove(s)
+	}
+}
+
+// slewDriver steps every moving slew from one clock timer, so the outputs
+// share a single SlewInterval tick instead of a timer each. The timer only
+// runs while something is moving. The OutputScheduler owns the driver for a
+// Controls' CVs.
+//
+// Lock order is slew.mu before slewDriver.mu; the tick steps the slews
+// without holding the driver's lock.
+type slewDriver struct {
+	clock clock.Clock
+
+	mu     sync.Mutex
+	moving map[*slew]struct{}
+	timer  clock.Timer
+	gen    uint64 // identifies the current timer, so a stopped one that still fires is ignored
+}
+
+func newSlewDriver(clk clock.Clock) *slewDriver {
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	return &slewDriver{clock: clk, moving: map[*slew]struct{}{}}
+}
+
+func (d *slewDriver) add(s *slew) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.moving[s] = struct{}{}
+	if d.timer == nil {
+		d.armLocked()
+	}
+}
+
+func (d *slewDriver) remove(s *slew) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.moving, s)
+	if len(d.moving) == 0 && d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+		d.gen++
+	}
+}
+
+func (d *slewDriver) armLocked() {
+	d.gen++
+	gen := d.gen
+	d.timer = d.clock.AfterFunc(SlewInterval, func() { d.tick(gen) })
+}
+
+func (d *slewDriver) tick(gen uint64) {
+	d.mu.Lock()
+	if gen != d.gen {
+		d.mu.Unlock()
+		return
+	}
+	d.timer = nil
+	slews := make([]*slew, 0, len(d.moving))
+	for s := range d.moving {
+		slews = append(slews, s)
+	}
+	d.mu.Unlock()
+
+	now := d.clock.Now()
+	for _, s := range slews {
+		s.step(now)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.moving) > 0 && d.timer == nil {
+		d.armLocked()
+	}
+}
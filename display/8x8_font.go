@@ -1,11 +1,7 @@
-//go:build tinygo
-
 package display
 
 import (
 	"image/color"
-
-	"tinygo.org/x/drivers/ssd1306"
 )
 
 // GetCharacterData returns the 8x8 bitmap data for a given ASCII character
@@ -19,7 +15,7 @@ func GetFont8x8CharacterData(char byte) []uint8 {
 }
 
 // DrawFont8x8Character draws a single character at the specified position
-func DrawFont8x8Character(display ssd1306.Device, x, y int16, char byte, c color.RGBA) {
+func DrawFont8x8Character(display ISSD1306Device, x, y int16, char byte, c color.RGBA) {
 	charData := GetFont8x8CharacterData(char)
 	if charData == nil {
 		return // Character not supported
@@ -39,17 +35,52 @@ func DrawFont8x8Character(display ssd1306.Device, x, y int16, char byte, c color
 }
 
 // DrawFont8x8Text draws text at the specified position, in c color
-func DrawFont8x8Text(display ssd1306.Device, x, y int16, text string, c color.RGBA) {
+func DrawFont8x8Text(display ISSD1306Device, x, y int16, text string, c color.RGBA) {
+	DrawFont8x8TextScaled(display, x, y, text, 1, c)
+}
+
+// DrawFont8x8TextScaled draws text with each font pixel scale x scale pixels
+// big, e.g. 2 for 16 pixel high text
+func DrawFont8x8TextScaled(display ISSD1306Device, x, y int16, text string, scale int16, c color.RGBA) {
+	if scale < 1 {
+		scale = 1
+	}
+	charW := 8 * scale
 	currentX := x
+	width, _ := display.Size()
 	textLen := len(text)
 	for i := 0; i < textLen; i++ {
 		// Check if we're going to exceed the display width
-		if currentX+8 > 128 {
+		if currentX+charW > width {
 			break // Stop drawing if we run out of horizontal space
 		}
 		// Draw character
-		DrawFont8x8Character(display, currentX, y, text[i], c)
-		currentX += 8 // Move to next character position (8 pixels wide)
+		if scale == 1 {
+			DrawFont8x8Character(display, currentX, y, text[i], c)
+		} else {
+			drawFont8x8CharacterScaled(display, currentX, y, text[i], scale, c)
+		}
+		currentX += charW // Move to next character position
+	}
+}
+
+// drawFont8x8CharacterScaled is DrawFont8x8Character with each pixel drawn as a scale x scale block
+func drawFont8x8CharacterScaled(display ISSD1306Device, x, y int16, char byte, scale int16, c color.RGBA) {
+	charData := GetFont8x8CharacterData(char)
+	if charData == nil {
+		return // Character not supported
+	}
+	for col := int16(0); col < 8; col++ {
+		for row := int16(0); row < 8; row++ {
+			if charData[col]&(1<<row) == 0 {
+				continue
+			}
+			for dx := int16(0); dx < scale; dx++ {
+				for dy := int16(0); dy < scale; dy++ {
+					display.SetPixel(x+col*scale+dx, y+row*scale+dy, c)
+				}
+			}
+		}
 	}
 }
 
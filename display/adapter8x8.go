@@ -0,0 +1,164 @@
+package display
+
+import "image/color"
+
+// SSD1306Adapter8x8 draws lines of text in the 8x8 font onto any
+// ISSD1306Device, the OLED on hardware or a Framebuffer on the host.
+type SSD1306Adapter8x8 struct {
+	// dev is the underlying SSD1306 device, x ranges from 0 to 127 (left to right), y from 0 to 31 or 63 (top to bottom)
+	dev ISSD1306Device
+	// layout holds the Y position and scale of each line, coord is the top of the font, drawn to bottom
+	layout Layout
+	// Highlight margins (in pixels)
+	HighlightMarginTop    int16
+	HighlightMarginBottom int16
+}
+
+// GetSSD1306 returns the underlying SSD1306 device.
+// On hardware its really a *ssd1306.Device, on the host a *Framebuffer
+func (o *SSD1306Adapter8x8) GetSSD1306() any {
+	return o.dev
+}
+
+// NewAdapter8x8 renders 8x8 text lines onto dev. Pass numLines = 1 to 4 (see LayoutForLines)
+func NewAdapter8x8(dev ISSD1306Device, numLines int) *SSD1306Adapter8x8 {
+	adapter := &SSD1306Adapter8x8{dev: dev}
+	adapter.SetNumLines(numLines)
+	return adapter
+}
+
+func (o *SSD1306Adapter8x8) ClearDisplay() {
+	o.dev.ClearDisplay()
+}
+
+func (o *SSD1306Adapter8x8) ClearBuffer() {
+	o.dev.ClearBuffer()
+}
+
+func (o *SSD1306Adapter8x8) Display() {
+	o.dev.Display()
+}
+
+// SetNumLines switches between the 1 to 4 line layouts and sets highlight margins
+func (o *SSD1306Adapter8x8) SetNumLines(numLines int) {
+	o.SetLayout(LayoutForLines(numLines))
+}
+
+// SetLayout switches layout, positioning its lines for the panel's height
+func (o *SSD1306Adapter8x8) SetLayout(layout Layout) {
+	_, height := o.dev.Size()
+	o.layout = layout.ForHeight(height)
+	o.HighlightMarginTop = layout.HighlightMargin
+	o.HighlightMarginBottom = layout.HighlightMargin
+}
+
+func (o *SSD1306Adapter8x8) Layout() Layout {
+	return o.layout
+}
+
+func (o *SSD1306Adapter8x8) NumLines() int {
+	return o.layout.NumLines()
+}
+
+// CharsPerLine is 16 on a 128 pixel wide display, the font being 8 pixels wide
+func (o *SSD1306Adapter8x8) CharsPerLine() int {
+	width, _ := o.dev.Size()
+	return int(width) / 8
+}
+
+func (o *SSD1306Adapter8x8) CharsOnLine(lineNum int) int {
+	return o.CharsPerLine() / int(o.layout.Scale(lineNum))
+}
+
+func (o *SSD1306Adapter8x8) Size() (width, height int16) {
+	return o.dev.Size()
+}
+
+func (o *SSD1306Adapter8x8) TextWidth(text string) int16 {
+	return int16(len(text) * 8)
+}
+
+func (o *SSD1306Adapter8x8) WriteLine(lineNum int, text string) {
+	if lineNum < 0 || lineNum >= o.layout.NumLines() {
+		return
+	}
+	y := o.layout.Lines[lineNum].Y
+	DrawFont8x8TextScaled(o.dev, 0, y, text, o.layout.Scale(lineNum), ColorWhite)
+}
+
+func (o *SSD1306Adapter8x8) WriteLineHighlighted(lineNum int, text string) {
+	if lineNum < 0 || lineNum >= o.layout.NumLines() {
+		return
+	}
+	y := o.layout.Lines[lineNum].Y
+	scale := o.layout.Scale(lineNum)
+	width, _ := o.dev.Size()
+	textW := int16(len(text)*8) * scale
+	if textW > width {
+		textW = width
+	}
+	textH := 8 * scale
+	rectX := int16(0)
+	rectY := y - o.HighlightMarginTop
+	rectW := textW
+	rectH := textH + o.HighlightMarginTop + o.HighlightMarginBottom
+	fillRectSafe(o.dev, rectX, rectY, rectW, rectH, ColorWhite)
+	DrawFont8x8TextScaled(o.dev, 0, y, text, scale, ColorBlack)
+}
+
+// WriteLineSpans writes a line with ranges of characters inverted or
+// underlined, e.g. to pick out the field being edited. Cursors are drawn
+// inverted; BufferedDisplay blinks them.
+func (o *SSD1306Adapter8x8) WriteLineSpans(lineNum int, text string, spans ...Span) {
+	if lineNum < 0 || lineNum >= o.layout.NumLines() {
+		return
+	}
+	o.WriteLine(lineNum, text)
+	drawSpans8x8(o.dev, o.layout.Lines[lineNum].Y, o.layout.Scale(lineNum), o.HighlightMarginTop, o.HighlightMarginBottom, text, spans)
+}
+
+// drawSpans8x8 draws the spans over a line of 8x8 text already drawn at y
+func drawSpans8x8(dev ISSD1306Device, y, scale, marginTop, marginBottom int16, text string, spans []Span) {
+	charW, charH := 8*scale, 8*scale
+	for _, s := range clipSpans(text, spans) {
+		x := int16(s.Start) * charW
+		w := int16(s.End-s.Start) * charW
+		switch s.Style {
+		case SpanUnderline:
+			// In the gap below the line, or the bottom row of the font when there's no gap
+			fillRectSafe(dev, x, y+charH-1+marginBottom, w, scale, ColorWhite)
+		case SpanInvert, SpanCursor:
+			fillRectSafe(dev, x, y-marginTop, w, charH+marginTop+marginBottom, ColorWhite)
+			DrawFont8x8TextScaled(dev, x, y, text[s.Start:s.End], scale, ColorBlack)
+		}
+	}
+}
+
+// fillRectSafe clamps the rectangle to the display area. Why: If you
+// attempt to draw a rectangle that has any pixel off-screen,
+// display.FillRectangle does nothing, so we clamp all values to ensure
+// something is always drawn.
+func fillRectSafe(display ISSD1306Device, x, y, w, h int16, c color.RGBA) {
+	// Clamp x and y to display bounds
+	if x < 0 {
+		w += x // reduce width by how much x is negative
+		x = 0
+	}
+	if y < 0 {
+		h += y // reduce height by how much y is negative
+		y = 0
+	}
+	// Clamp width and height so rectangle stays within display
+	width, height := display.Size()
+	if x+w > width {
+		w = width - x
+	}
+	if y+h > height {
+		h = height - y
+	}
+	// If rectangle is completely off-screen, do nothing
+	if w <= 0 || h <= 0 {
+		return
+	}
+	display.FillRectangle(x, y, w, h, c)
+}
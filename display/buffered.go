@@ -1,5 +1,10 @@
 package display
 
+import (
+	"europi/clock"
+	"time"
+)
+
 // BufferedDisplay wraps an IOledDevice and only updates the underlying device when the buffer
 // (intended display state) differs from the last displayed state, minimizing unnecessary redraws and flicker.
 type BufferedDisplay struct {
@@ -9,12 +14,30 @@ type BufferedDisplay struct {
 	dirty                    bool        // True if buffer differs from last displayed state
 	lastDisplayedLines       []string    // Last lines actually sent to the display
 	lastDisplayedHighlighted []bool      // Last highlight state actually sent to the display
+	spans                    [][]Span    // Current spans per line, from WriteLineSpans
+	lastDisplayedSpans       [][]Span    // Last spans actually sent, with the cursors blinked (see visibleSpans)
 	numLines                 int         // Number of lines (3 or 4)
+
+	// Clock times scrolling lines, Marquee sets how they scroll
+	Clock     clock.Clock
+	Marquee   Marquee
+	scrolling []scrollingLine
+}
+
+// scrollingLine is a line written with WriteLineScrolling. It survives
+// ClearBuffer, so an app redrawing the same text every frame doesn't restart it,
+// but is forgotten once a frame is displayed without it.
+type scrollingLine struct {
+	text   string
+	start  time.Time
+	active bool // written since the last clear
 }
 
 func NewBufferedDisplay(real IOledDevice, numLines int) *BufferedDisplay {
 	m := &BufferedDisplay{
 		Backend: real,
+		Clock:   clock.Real{},
+		Marquee: DefaultMarquee,
 	}
 	m.SetNumLines(numLines)
 	return m
@@ -25,25 +48,56 @@ func (m *BufferedDisplay) GetSSD1306() any {
 }
 
 func (m *BufferedDisplay) SetNumLines(numLines int) {
-	if numLines < 3 || numLines > 4 {
-		panic("numLines must be 3 or 4")
-	}
+	m.SetLayout(LayoutForLines(numLines))
+}
+
+// SetLayout resets the buffer for the layout's lines and passes the layout on to the backend.
+func (m *BufferedDisplay) SetLayout(layout Layout) {
+	numLines := layout.NumLines()
 	m.numLines = numLines
 	m.Lines = make([]string, numLines)
 	m.highlighted = make([]bool, numLines)
 	m.lastDisplayedLines = make([]string, numLines)
 	m.lastDisplayedHighlighted = make([]bool, numLines)
+	m.spans = make([][]Span, numLines)
+	m.lastDisplayedSpans = make([][]Span, numLines)
+	m.scrolling = make([]scrollingLine, numLines)
+
+	m.Backend.SetLayout(layout) // Call the underlying device's SetLayout to ensure it knows the line count
 
-	m.Backend.SetNumLines(numLines) // Call the underlying device's SetNumLines to ensure it knows the line count
-	
 	m.dirty = true // Mark dirty so next display updates backend
 }
 
-// NumLines returns the number of lines (3 or 4) for the display.
+// Layout returns the backend's layout.
+func (m *BufferedDisplay) Layout() Layout {
+	return m.Backend.Layout()
+}
+
+// NumLines returns the number of lines (1 to 4) for the display.
 func (m *BufferedDisplay) NumLines() int {
 	return m.numLines
 }
 
+// CharsPerLine returns the backend's characters per line.
+func (m *BufferedDisplay) CharsPerLine() int {
+	return m.Backend.CharsPerLine()
+}
+
+// CharsOnLine returns the backend's characters on a line of the layout.
+func (m *BufferedDisplay) CharsOnLine(lineNum int) int {
+	return m.Backend.CharsOnLine(lineNum)
+}
+
+// Size returns the backend's size in pixels.
+func (m *BufferedDisplay) Size() (width, height int16) {
+	return m.Backend.Size()
+}
+
+// TextWidth measures text in the backend's font.
+func (m *BufferedDisplay) TextWidth(text string) int16 {
+	return m.Backend.TextWidth(text)
+}
+
 // WriteLine updates the buffer for the given line and removes highlight for that line.
 // No backend calls are made until Display or DisplayString. Sets dirty only if the buffer differs from the last displayed state.
 func (m *BufferedDisplay) WriteLine(lineNum int, text string) {
@@ -52,9 +106,52 @@ func (m *BufferedDisplay) WriteLine(lineNum int, text string) {
 	}
 	m.Lines[lineNum] = text
 	m.highlighted[lineNum] = false
+	m.spans[lineNum] = nil
+	m.scrolling[lineNum] = scrollingLine{}
 	m.dirty = !m.isBufferEqualToLastDisplayed()
 }
 
+// WriteLineScrolling writes a line that scrolls back and forth with Marquee
+// if it's too long for the display. The scrolling carries on across
+// ClearBuffer as long as the same text is written again, so keep calling
+// Display, e.g. every 100ms, to animate it.
+func (m *BufferedDisplay) WriteLineScrolling(lineNum int, text string) {
+	if lineNum < 0 || lineNum >= len(m.Lines) {
+		return
+	}
+	line := &m.scrolling[lineNum]
+	if line.text != text || line.start.IsZero() {
+		line.text = text
+		line.start = m.Clock.Now()
+	}
+	line.active = true
+	m.highlighted[lineNum] = false
+	m.spans[lineNum] = nil
+	m.Lines[lineNum] = m.scrollWindow(lineNum)
+	m.dirty = !m.isBufferEqualToLastDisplayed()
+}
+
+func (m *BufferedDisplay) scrollWindow(lineNum int) string {
+	line := &m.scrolling[lineNum]
+	return m.Marquee.Window(line.text, m.Backend.CharsOnLine(lineNum), m.Clock.Now().Sub(line.start))
+}
+
+// updateScrolling moves scrolling lines along, marking the buffer dirty if
+// any moved or a cursor blinked. Lines cleared and not written again are
+// dropped, so the same text shown later starts from the beginning.
+func (m *BufferedDisplay) updateScrolling() {
+	for i := range m.scrolling {
+		if m.scrolling[i].active {
+			m.Lines[i] = m.scrollWindow(i)
+		} else {
+			m.scrolling[i] = scrollingLine{}
+		}
+	}
+	if !m.isBufferEqualToLastDisplayed() {
+		m.dirty = true
+	}
+}
+
 // WriteLineHighlighted updates the buffer for the given line and sets highlight for that line.
 // No backend calls are made until Display or DisplayString. Sets dirty only if the buffer differs from the last displayed state.
 func (m *BufferedDisplay) WriteLineHighlighted(lineNum int, text string) {
@@ -63,15 +160,55 @@ func (m *BufferedDisplay) WriteLineHighlighted(lineNum int, text string) {
 	}
 	m.Lines[lineNum] = text
 	m.highlighted[lineNum] = true
+	m.spans[lineNum] = nil
+	m.scrolling[lineNum] = scrollingLine{}
 	m.dirty = !m.isBufferEqualToLastDisplayed()
 }
 
+// WriteLineSpans updates the buffer for the given line, with the spans inverted,
+// underlined or showing a cursor. A line with a cursor needs Display calling
+// regularly, e.g. every 100ms, to blink it.
+// No backend calls are made until Display or DisplayString. Sets dirty only if the buffer differs from the last displayed state.
+func (m *BufferedDisplay) WriteLineSpans(lineNum int, text string, spans ...Span) {
+	if lineNum < 0 || lineNum >= len(m.Lines) {
+		return
+	}
+	m.Lines[lineNum] = text
+	m.highlighted[lineNum] = false
+	m.spans[lineNum] = append([]Span{}, spans...)
+	m.scrolling[lineNum] = scrollingLine{}
+	m.dirty = !m.isBufferEqualToLastDisplayed()
+}
+
+// visibleSpans returns the line's spans with the cursors blinked on m.Clock:
+// a cursor that is showing becomes an inverted span, one that is blinked off
+// is left out. The backend never sees a cursor, so it doesn't need a clock.
+func (m *BufferedDisplay) visibleSpans(lineNum int) []Span {
+	spans := m.spans[lineNum]
+	if spans == nil {
+		return nil
+	}
+	visible := []Span{}
+	for _, s := range spans {
+		if s.Style == SpanCursor {
+			if !CursorVisible(m.Clock) {
+				continue
+			}
+			s.Style = SpanInvert
+		}
+		visible = append(visible, s)
+	}
+	return visible
+}
+
 // ClearDisplay resets the buffer to an empty state. No backend calls are made until Display or DisplayString.
 // Sets dirty only if the buffer differs from the last displayed state.
 func (m *BufferedDisplay) ClearDisplay() {
 	for i := range m.Lines {
 		m.Lines[i] = ""
 		m.highlighted[i] = false
+		m.spans[i] = nil
+		m.scrolling[i].active = false
 	}
 	m.dirty = !m.isBufferEqualToLastDisplayed()
 }
@@ -86,15 +223,14 @@ func (m *BufferedDisplay) ClearBuffer() {
 // DisplayString pushes all buffered changes to the backend mock and returns the current display as a string.
 // After returning, it updates the last displayed state. Used for testing.
 func (m *BufferedDisplay) DisplayString() string {
+	m.updateScrolling()
 	if !m.dirty {
 		return ""
 	}
 	m.flushBufferToBackend()
 	if displayStringer, ok := m.Backend.(interface{ DisplayString() string }); ok {
 		result := displayStringer.DisplayString()
-		copy(m.lastDisplayedLines, m.Lines)
-		copy(m.lastDisplayedHighlighted, m.highlighted)
-		m.dirty = false
+		m.markDisplayed()
 		return result
 	}
 	return ""
@@ -102,13 +238,22 @@ func (m *BufferedDisplay) DisplayString() string {
 
 // Display pushes all buffered changes to the backend and updates the last displayed state.
 func (m *BufferedDisplay) Display() {
+	m.updateScrolling()
 	if !m.dirty {
 		return
 	}
 	m.flushBufferToBackend()
 	m.Backend.Display()
+	m.markDisplayed()
+}
+
+// markDisplayed records the buffer as the last displayed state.
+func (m *BufferedDisplay) markDisplayed() {
 	copy(m.lastDisplayedLines, m.Lines)
 	copy(m.lastDisplayedHighlighted, m.highlighted)
+	for i := range m.spans {
+		m.lastDisplayedSpans[i] = m.visibleSpans(i)
+	}
 	m.dirty = false
 }
 
@@ -118,6 +263,8 @@ func (m *BufferedDisplay) flushBufferToBackend() {
 	for i := range m.Lines {
 		if m.highlighted[i] {
 			m.Backend.WriteLineHighlighted(i, m.Lines[i])
+		} else if m.spans[i] != nil {
+			m.Backend.WriteLineSpans(i, m.Lines[i], m.visibleSpans(i)...)
 		} else {
 			m.Backend.WriteLine(i, m.Lines[i])
 		}
@@ -133,6 +280,23 @@ func (m *BufferedDisplay) isBufferEqualToLastDisplayed() bool {
 		if m.Lines[i] != m.lastDisplayedLines[i] || m.highlighted[i] != m.lastDisplayedHighlighted[i] {
 			return false
 		}
+		if !spansEqual(m.visibleSpans(i), m.lastDisplayedSpans[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// spansEqual compares spans, telling a line without spans (nil) from one
+// whose only cursor is blinked off (empty).
+func spansEqual(a, b []Span) bool {
+	if (a == nil) != (b == nil) || len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
 	}
 	return true
 }
@@ -0,0 +1,91 @@
+//go:build !tinygo
+
+// Package displaytest compares display frames against golden PNGs kept in
+// the calling package's testdata directory.
+//
+// Run the tests with UPDATE_GOLDEN=1 to write (or rewrite) the golden images,
+// then look at them before committing.
+package displaytest
+
+import (
+	"europi/display"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// GoldenDir is where golden images live, relative to the test's package.
+var GoldenDir = "testdata"
+
+// AssertGolden fails the test if fb doesn't match testdata/<name>.png.
+// On a mismatch the actual frame is saved next to it as <name>.actual.png
+// and the differing pixels are logged.
+func AssertGolden(t testing.TB, fb *display.Framebuffer, name string) {
+	t.Helper()
+	path := filepath.Join(GoldenDir, name+".png")
+	actualPath := filepath.Join(GoldenDir, name+".actual.png")
+
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		if err := os.MkdirAll(GoldenDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := fb.SavePNG(path); err != nil {
+			t.Fatal(err)
+		}
+		os.Remove(actualPath)
+		return
+	}
+
+	want, err := display.LoadPNG(path)
+	if err != nil {
+		t.Fatalf("golden image %s: %v (run with UPDATE_GOLDEN=1 to create it)", path, err)
+	}
+	if diff := Diff(want, fb); diff != "" {
+		if err := fb.SavePNG(actualPath); err != nil {
+			t.Log("saving actual frame:", err)
+		}
+		t.Errorf("frame doesn't match %s, actual saved to %s\n%s", path, actualPath, diff)
+		return
+	}
+	os.Remove(actualPath)
+}
+
+// Diff returns "" if the frames are the same, otherwise a picture of the
+// differences: '+' is on but should be off, '-' is off but should be on.
+func Diff(want, got *display.Framebuffer) string {
+	ww, wh := want.Size()
+	gw, gh := got.Size()
+	if ww != gw || wh != gh {
+		return "size differs: want " + sizeString(ww, wh) + ", got " + sizeString(gw, gh)
+	}
+	var b strings.Builder
+	differs := false
+	for y := int16(0); y < wh; y++ {
+		for x := int16(0); x < ww; x++ {
+			w, g := want.GetPixel(x, y), got.GetPixel(x, y)
+			switch {
+			case w == g && g:
+				b.WriteByte('#')
+			case w == g:
+				b.WriteByte('.')
+			case g:
+				b.WriteByte('+')
+				differs = true
+			default:
+				b.WriteByte('-')
+				differs = true
+			}
+		}
+		b.WriteByte('\n')
+	}
+	if !differs {
+		return ""
+	}
+	return b.String()
+}
+
+func sizeString(w, h int16) string {
+	return fmt.Sprintf("%dx%d", w, h)
+}
@@ -0,0 +1,46 @@
+package displaytest
+
+import (
+	"europi/display"
+	"strings"
+	"testing"
+)
+
+// testPattern draws a border, a filled box and a diagonal, enough to catch
+// off by one and orientation mistakes in the PNG round trip.
+func testPattern() *display.Framebuffer {
+	fb := display.NewFramebuffer(display.EuroPiPanel.Width, display.EuroPiPanel.Height)
+	w, h := fb.Size()
+	for x := int16(0); x < w; x++ {
+		fb.SetPixel(x, 0, display.ColorWhite)
+		fb.SetPixel(x, h-1, display.ColorWhite)
+	}
+	for y := int16(0); y < h; y++ {
+		fb.SetPixel(0, y, display.ColorWhite)
+		fb.SetPixel(w-1, y, display.ColorWhite)
+		fb.SetPixel(y+10, y, display.ColorWhite)
+	}
+	fb.FillRectangle(90, 8, 20, 10, display.ColorWhite)
+	return fb
+}
+
+func TestGoldenPattern(t *testing.T) {
+	AssertGolden(t, testPattern(), "pattern")
+}
+
+func TestDiff(t *testing.T) {
+	want := testPattern()
+	got := testPattern()
+	if d := Diff(want, got); d != "" {
+		t.Fatalf("Expected identical frames to have no diff, got\n%s", d)
+	}
+	got.SetPixel(5, 5, display.ColorWhite)
+	got.SetPixel(0, 0, display.ColorBlack)
+	d := Diff(want, got)
+	if strings.Count(d, "+") != 1 || strings.Count(d, "-") != 1 {
+		t.Errorf("Expected one extra and one missing pixel in diff, got\n%s", d)
+	}
+	if d := Diff(want, display.NewFramebuffer(128, 64)); !strings.HasPrefix(d, "size differs") {
+		t.Errorf("Expected size mismatch, got %q", d)
+	}
+}
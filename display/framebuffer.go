@@ -0,0 +1,151 @@
+package display
+
+import (
+	"errors"
+	"image/color"
+	"strings"
+	"sync"
+)
+
+// Framebuffer is an in-memory monochrome display that behaves like the
+// SSD1306 driver: it implements ISSD1306Device, ignores pixels drawn off
+// screen and rejects rectangles that don't fit, just like the hardware does.
+// The mocks return it from GetSSD1306 so pixel apps run on the host.
+type Framebuffer struct {
+	mu     sync.Mutex
+	width  int16
+	height int16
+	pixels []bool
+	frames int
+	// OnDisplay, if set, is called by Display with the framebuffer, e.g. to
+	// show or save the frame. It runs without the framebuffer lock held.
+	OnDisplay func(fb *Framebuffer)
+}
+
+func NewFramebuffer(width, height int16) *Framebuffer {
+	return &Framebuffer{width: width, height: height, pixels: make([]bool, int(width)*int(height))}
+}
+
+func (f *Framebuffer) Size() (width, height int16) {
+	return f.width, f.height
+}
+
+// SetPixel turns a pixel on for any colour that isn't black, as the SSD1306 driver does.
+func (f *Framebuffer) SetPixel(x, y int16, c color.RGBA) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.setLocked(x, y, c.R != 0 || c.G != 0 || c.B != 0)
+}
+
+func (f *Framebuffer) setLocked(x, y int16, on bool) {
+	if x < 0 || y < 0 || x >= f.width || y >= f.height {
+		return
+	}
+	f.pixels[int(y)*int(f.width)+int(x)] = on
+}
+
+// GetPixel reports whether the pixel is on. Pixels off screen are off.
+func (f *Framebuffer) GetPixel(x, y int16) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if x < 0 || y < 0 || x >= f.width || y >= f.height {
+		return false
+	}
+	return f.pixels[int(y)*int(f.width)+int(x)]
+}
+
+// FillRectangle fills the rectangle, or returns an error without drawing
+// anything if it doesn't fit on the screen (see fillRectSafe for why that matters).
+func (f *Framebuffer) FillRectangle(x, y, width, height int16, c color.RGBA) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if x < 0 || y < 0 || width <= 0 || height <= 0 ||
+		x >= f.width || x+width > f.width || y >= f.height || y+height > f.height {
+		return errors.New("invalid rectangle")
+	}
+	on := c.R != 0 || c.G != 0 || c.B != 0
+	for j := y; j < y+height; j++ {
+		for i := x; i < x+width; i++ {
+			f.setLocked(i, j, on)
+		}
+	}
+	return nil
+}
+
+func (f *Framebuffer) ClearBuffer() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.pixels {
+		f.pixels[i] = false
+	}
+}
+
+// ClearDisplay clears the buffer and shows the empty frame.
+func (f *Framebuffer) ClearDisplay() {
+	f.ClearBuffer()
+	f.Display()
+}
+
+// Display counts the frame and hands it to OnDisplay.
+func (f *Framebuffer) Display() error {
+	f.mu.Lock()
+	f.frames++
+	onDisplay := f.OnDisplay
+	f.mu.Unlock()
+	if onDisplay != nil {
+		onDisplay(f)
+	}
+	return nil
+}
+
+// Frames returns the number of times Display has been called.
+func (f *Framebuffer) Frames() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.frames
+}
+
+// String draws the buffer one character per pixel, '#' for on and '.' for off.
+func (f *Framebuffer) String() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var b strings.Builder
+	for y := 0; y < int(f.height); y++ {
+		for x := 0; x < int(f.width); x++ {
+			if f.pixels[y*int(f.width)+x] {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// HalfBlocks draws the buffer with Unicode half blocks, two pixel rows per
+// line of text, so a 128x32 screen fits in a terminal as 128x16.
+func (f *Framebuffer) HalfBlocks() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var lines []string
+	for y := 0; y < int(f.height); y += 2 {
+		var b strings.Builder
+		for x := 0; x < int(f.width); x++ {
+			top := f.pixels[y*int(f.width)+x]
+			bottom := y+1 < int(f.height) && f.pixels[(y+1)*int(f.width)+x]
+			switch {
+			case top && bottom:
+				b.WriteRune('█')
+			case top:
+				b.WriteRune('▀')
+			case bottom:
+				b.WriteRune('▄')
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		lines = append(lines, b.String())
+	}
+	return lines
+}
@@ -0,0 +1,96 @@
+//go:build !tinygo
+
+package display
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Image returns the buffer as a grayscale image, one image pixel per OLED pixel.
+func (f *Framebuffer) Image() *image.Gray {
+	width, height := f.Size()
+	img := image.NewGray(image.Rect(0, 0, int(width), int(height)))
+	for y := int16(0); y < height; y++ {
+		for x := int16(0); x < width; x++ {
+			if f.GetPixel(x, y) {
+				img.SetGray(int(x), int(y), color.Gray{Y: 255})
+			}
+		}
+	}
+	return img
+}
+
+// WritePNG writes the buffer as a PNG, each OLED pixel scaled up to scale x scale.
+func (f *Framebuffer) WritePNG(w io.Writer, scale int) error {
+	if scale < 1 {
+		scale = 1
+	}
+	src := f.Image()
+	if scale == 1 {
+		return png.Encode(w, src)
+	}
+	b := src.Bounds()
+	img := image.NewGray(image.Rect(0, 0, b.Dx()*scale, b.Dy()*scale))
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			img.SetGray(x, y, src.GrayAt(x/scale, y/scale))
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// SavePNG writes the buffer to a PNG file at 1:1 scale.
+func (f *Framebuffer) SavePNG(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := f.WritePNG(file, 1); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// FramebufferFromImage turns an image into a Framebuffer, pixels brighter than mid grey being on.
+func FramebufferFromImage(img image.Image) *Framebuffer {
+	b := img.Bounds()
+	f := NewFramebuffer(int16(b.Dx()), int16(b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			if color.GrayModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.Gray).Y >= 128 {
+				f.SetPixel(int16(x), int16(y), ColorWhite)
+			}
+		}
+	}
+	return f
+}
+
+// LoadPNG reads a PNG written by SavePNG.
+func LoadPNG(path string) (*Framebuffer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	return FramebufferFromImage(img), nil
+}
+
+// saveSnapshot writes fb to dir as frame-NNNNN.png, numbered by n. Errors are
+// printed rather than returned so a full disk doesn't stop the app.
+func saveSnapshot(dir string, n int, fb *Framebuffer) {
+	path := filepath.Join(dir, fmt.Sprintf("frame-%05d.png", n))
+	if err := fb.SavePNG(path); err != nil {
+		println("snapshot:", err.Error())
+	}
+}
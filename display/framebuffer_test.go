@@ -0,0 +1,111 @@
+// Framebuffer tests
+package display
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFramebufferPixels(t *testing.T) {
+	fb := NewFramebuffer(128, 32)
+	fb.SetPixel(0, 0, ColorWhite)
+	fb.SetPixel(127, 31, ColorWhite)
+	fb.SetPixel(128, 0, ColorWhite) // off screen, ignored like on the OLED
+	fb.SetPixel(-1, 5, ColorWhite)
+	if !fb.GetPixel(0, 0) || !fb.GetPixel(127, 31) || fb.GetPixel(1, 0) {
+		t.Error("Expected exactly the corner pixels to be set")
+	}
+	fb.SetPixel(0, 0, ColorBlack)
+	if fb.GetPixel(0, 0) {
+		t.Error("Expected black to clear a pixel")
+	}
+
+	// Rectangles that don't fit are rejected, just like the ssd1306 driver
+	if err := fb.FillRectangle(120, 0, 10, 4, ColorWhite); err == nil {
+		t.Error("Expected an off screen rectangle to fail")
+	}
+	if fb.GetPixel(120, 0) {
+		t.Error("Expected a rejected rectangle to draw nothing")
+	}
+	if err := fb.FillRectangle(2, 1, 3, 2, ColorWhite); err != nil {
+		t.Fatal(err)
+	}
+	small := NewFramebuffer(6, 4)
+	small.FillRectangle(2, 1, 3, 2, ColorWhite)
+	want := "" +
+		"......\n" +
+		"..###.\n" +
+		"..###.\n" +
+		"......\n"
+	if got := small.String(); got != want {
+		t.Errorf("Expected:\n%s\nGot:\n%s", want, got)
+	}
+	if got := small.HalfBlocks(); len(got) != 2 || got[0] != "  ▄▄▄ " || got[1] != "  ▀▀▀ " {
+		t.Errorf("Unexpected half blocks %q", got)
+	}
+
+	fb.ClearBuffer()
+	if fb.GetPixel(127, 31) || fb.GetPixel(2, 1) {
+		t.Error("Expected ClearBuffer to clear every pixel")
+	}
+}
+
+func TestMockReturnsFramebuffer(t *testing.T) {
+	oled := NewMockOledDevice(3, 16)
+	ssd, ok := oled.GetSSD1306().(ISSD1306Device)
+	if !ok {
+		t.Fatal("Expected the mock to provide an ISSD1306Device for pixel apps")
+	}
+	frames := 0
+	oled.Framebuffer().OnDisplay = func(*Framebuffer) { frames++ }
+	ssd.SetPixel(10, 10, ColorWhite)
+	ssd.Display()
+	if frames != 1 || oled.Framebuffer().Frames() != 1 || !oled.Framebuffer().GetPixel(10, 10) {
+		t.Error("Expected the pixel and the frame to reach the framebuffer")
+	}
+	oled.ClearDisplay()
+	if oled.Framebuffer().GetPixel(10, 10) {
+		t.Error("Expected ClearDisplay to clear the pixels too")
+	}
+}
+
+func TestFramebufferPNGAndSnapshots(t *testing.T) {
+	fb := NewFramebuffer(8, 4)
+	fb.SetPixel(1, 2, ColorWhite)
+	fb.SetPixel(7, 3, ColorWhite)
+	path := filepath.Join(t.TempDir(), "frame.png")
+	if err := fb.SavePNG(path); err != nil {
+		t.Fatal(err)
+	}
+	back, err := LoadPNG(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.String() != fb.String() {
+		t.Errorf("Expected PNG round trip to keep the pixels, got:\n%s", back.String())
+	}
+
+	oled := NewMockOledDevice(3, 16)
+	oled.SnapshotDir = t.TempDir()
+	oled.Framebuffer().Display()
+	oled.Framebuffer().Display()
+	for _, name := range []string{"frame-00001.png", "frame-00002.png"} {
+		if _, err := os.Stat(filepath.Join(oled.SnapshotDir, name)); err != nil {
+			t.Errorf("Expected snapshot %s: %v", name, err)
+		}
+	}
+}
+
+func TestFillRectSafeClamps(t *testing.T) {
+	fb := NewFramebuffer(128, 32)
+	fillRectSafe(fb, 120, -2, 20, 4, ColorWhite)
+	if !fb.GetPixel(127, 0) || !fb.GetPixel(120, 1) || fb.GetPixel(120, 2) {
+		t.Error("Expected the rectangle to be clamped to the display")
+	}
+	fillRectSafe(fb, 0, 32, 10, 4, ColorWhite) // completely off screen
+	fillRectSafe(fb, 0, 10, 10, 0, ColorWhite) // empty
+	if fb.GetPixel(0, 31) || fb.GetPixel(0, 10) {
+		t.Error("Expected nothing drawn for an off screen or empty rectangle")
+	}
+}
@@ -4,9 +4,12 @@ package display
 import "image/color"
 
 type IOledDevice interface {
-	// 3 or 4 lines for OLED display
+	// 3 or 4 lines for OLED display, or 1 or 2 lines of large text (see LayoutForLines)
 	NumLines() int
 	SetNumLines(n int)
+	// SetLayout switches to any layout, e.g. LayoutLargeWithStatus
+	SetLayout(layout Layout)
+	Layout() Layout
 	// Returns the underlying SSD1306 device if available, otherwise nil (for mocks)
 	GetSSD1306() any
 	// ClearDisplay clears the display content.
@@ -17,6 +20,18 @@ type IOledDevice interface {
 	// WriteLine writes a line of text to the display at the specified line number.
 	WriteLine(lineNum int, text string)
 	WriteLineHighlighted(lineNum int, text string)
+	// WriteLineSpans writes a line with parts of it inverted, underlined or showing a cursor (see Span)
+	WriteLineSpans(lineNum int, text string, spans ...Span)
+	// CharsPerLine is how many characters fit across the display, 16 for the 8x8 font, 21 for TinyFont.
+	// Large lines fit fewer (see CharsOnLine)
+	CharsPerLine() int
+	// CharsOnLine is how many characters fit on a line of the current layout,
+	// fewer than CharsPerLine on large lines
+	CharsOnLine(lineNum int) int
+	// Size is the display's width and height in pixels
+	Size() (width, height int16)
+	// TextWidth is how many pixels wide text is in the display's font (see Truncate and Ellipsis)
+	TextWidth(text string) int16
 }
 
 // Common color constants for OLED rendering
@@ -27,6 +42,7 @@ var (
 
 // The raw device not my higher level IOledDevice
 type ISSD1306Device interface {
+	Size() (width, height int16)
 	SetPixel(x, y int16, c color.RGBA)
 	Display() error
 	ClearDisplay()
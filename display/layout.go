@@ -0,0 +1,88 @@
+package display
+
+// LayoutLine is one line of a Layout.
+type LayoutLine struct {
+	Y      int16 // pixel coordinate of the top of the line, worked out by ForHeight
+	Scale  int16 // 1 for the normal 8 pixel font, 2 for double size and so on
+	Bottom bool  // sits on the bottom of the panel, e.g. a status line
+}
+
+// Layout is how lines of text are arranged on the display. Lines can be
+// different sizes, e.g. a large readout with a small status line under it.
+type Layout struct {
+	Name  string
+	Lines []LayoutLine
+	// Highlight margins (in pixels) above and below a highlighted line
+	HighlightMargin int16
+}
+
+// The built in layouts, positioned for a 32 pixel high display. SetNumLines
+// picks one of the first four; use SetLayout for the others. The displays
+// position them for their own panel height with ForHeight.
+var (
+	// LayoutBigDigits is one line of 3x size text for a BPM or voltage readout, 5 characters wide.
+	LayoutBigDigits = Layout{Name: "big digits", Lines: []LayoutLine{{Scale: 3}}}.ForHeight(32)
+	// Layout2Lines is two lines of double size text, 8 characters wide.
+	Layout2Lines = Layout{Name: "2 lines", Lines: []LayoutLine{{Scale: 2}, {Scale: 2}}}.ForHeight(32)
+	Layout3Lines = Layout{Name: "3 lines", Lines: []LayoutLine{{Scale: 1}, {Scale: 1}, {Scale: 1}}, HighlightMargin: 1}.ForHeight(32)
+	Layout4Lines = Layout{Name: "4 lines", Lines: []LayoutLine{{Scale: 1}, {Scale: 1}, {Scale: 1}, {Scale: 1}}}.ForHeight(32)
+	// LayoutLargeWithStatus is a double size line with a normal status line under it.
+	LayoutLargeWithStatus = Layout{Name: "large with status", Lines: []LayoutLine{{Scale: 2}, {Scale: 1, Bottom: true}}}.ForHeight(32)
+)
+
+// ForHeight returns the layout with its lines positioned on a panel height
+// pixels high. The space left over by the text is shared out evenly above,
+// between and below the lines, and Bottom lines sit one pixel above the
+// bottom edge.
+func (l Layout) ForHeight(height int16) Layout {
+	var textH int16
+	for i := range l.Lines {
+		textH += 8 * l.Scale(i)
+	}
+	gap := (height - textH) / int16(len(l.Lines)+1)
+	if gap < 0 {
+		gap = 0
+	}
+	lines := make([]LayoutLine, len(l.Lines))
+	y := gap
+	for i, line := range l.Lines {
+		h := 8 * l.Scale(i)
+		line.Y = y
+		if line.Bottom {
+			line.Y = height - h - 1
+		}
+		lines[i] = line
+		y += h + gap
+	}
+	l.Lines = lines
+	return l
+}
+
+// LayoutForLines returns the built in layout with numLines lines, 1 to 4.
+// It panics for anything else, like SetNumLines always has.
+func LayoutForLines(numLines int) Layout {
+	switch numLines {
+	case 1:
+		return LayoutBigDigits
+	case 2:
+		return Layout2Lines
+	case 3:
+		return Layout3Lines
+	case 4:
+		return Layout4Lines
+	}
+	panic("numLines must be 1 to 4")
+}
+
+// NumLines returns the number of lines in the layout.
+func (l Layout) NumLines() int {
+	return len(l.Lines)
+}
+
+// Scale returns the scale of a line, 1 if it's out of range.
+func (l Layout) Scale(lineNum int) int16 {
+	if lineNum < 0 || lineNum >= len(l.Lines) || l.Lines[lineNum].Scale < 1 {
+		return 1
+	}
+	return l.Lines[lineNum].Scale
+}
@@ -0,0 +1,83 @@
+// Layout tests
+package display
+
+import "testing"
+
+func TestLayouts(t *testing.T) {
+	for n := 1; n <= 4; n++ {
+		if got := LayoutForLines(n).NumLines(); got != n {
+			t.Errorf("Expected layout for %d lines to have %d, got %d", n, n, got)
+		}
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected 5 lines to panic")
+			}
+		}()
+		LayoutForLines(5)
+	}()
+
+	oled := NewMockOledDevice(3, 16)
+	buffered := NewBufferedDisplay(oled, 3)
+	buffered.SetLayout(LayoutLargeWithStatus)
+	if oled.Layout().Name != LayoutLargeWithStatus.Name || buffered.NumLines() != 2 || len(oled.LinesRaw) != 2 {
+		t.Fatal("Expected BufferedDisplay to pass the layout on to the backend")
+	}
+	if buffered.CharsOnLine(0) != 8 || buffered.CharsOnLine(1) != 16 {
+		t.Errorf("Expected 8 large or 16 small chars, got %d and %d", buffered.CharsOnLine(0), buffered.CharsOnLine(1))
+	}
+	buffered.WriteLine(0, "120 BPM and more")
+	buffered.WriteLine(1, "Status")
+	buffered.Display()
+	if oled.LinesRaw[0] != "120 BPM " || oled.LinesRaw[1] != "Status" {
+		t.Errorf("Expected the large line cut to 8 chars, got %q", oled.LinesRaw)
+	}
+
+	oled.SetNumLines(1)
+	if oled.Layout().Name != LayoutBigDigits.Name || oled.CharsOnLine(0) != 5 {
+		t.Errorf("Expected 5 big digits, got %d", oled.CharsOnLine(0))
+	}
+
+	// The adapters count the characters of the font a line is drawn in
+	adapter := NewAdapter8x8(NewFramebuffer(128, 32), 2)
+	if adapter.CharsOnLine(0) != 8 || adapter.CharsOnLine(5) != 16 {
+		t.Errorf("Expected 8 chars on a double size line, got %d", adapter.CharsOnLine(0))
+	}
+}
+
+func TestLayoutForHeight(t *testing.T) {
+	// On the original EuroPi's 32 pixel panel the lines are where they've always been
+	for _, tc := range []struct {
+		layout Layout
+		ys     []int16
+	}{
+		{LayoutBigDigits, []int16{4}},
+		{Layout2Lines, []int16{0, 16}},
+		{Layout3Lines, []int16{2, 12, 22}},
+		{Layout4Lines, []int16{0, 8, 16, 24}},
+		{LayoutLargeWithStatus, []int16{2, 23}},
+	} {
+		for i, y := range tc.ys {
+			if got := tc.layout.ForHeight(32).Lines[i].Y; got != y {
+				t.Errorf("%s: expected line %d at y=%d, got %d", tc.layout.Name, i, y, got)
+			}
+		}
+	}
+
+	// On a 64 pixel panel they spread out to fill it
+	l := Layout3Lines.ForHeight(64)
+	if l.Lines[0].Y != 10 || l.Lines[1].Y != 28 || l.Lines[2].Y != 46 {
+		t.Errorf("Expected 3 lines at y=10, 28 and 46, got %+v", l.Lines)
+	}
+	if l := LayoutLargeWithStatus.ForHeight(64); l.Lines[1].Y != 55 {
+		t.Errorf("Expected the status line on the bottom row, got y=%d", l.Lines[1].Y)
+	}
+
+	// Displays position the layout for their panel
+	fb := NewFramebuffer(128, 64)
+	oled := NewAdapter8x8(fb, 4)
+	if got := oled.Layout().Lines[3].Y; got != 48 {
+		t.Errorf("Expected the 4th line at y=48 on a 64 pixel panel, got %d", got)
+	}
+}
@@ -0,0 +1,61 @@
+package display
+
+import "time"
+
+// Marquee scrolls a line too long for the display back and forth, holding
+// for Pause at each end so both ends can be read.
+type Marquee struct {
+	Step  time.Duration // time to scroll by one character
+	Pause time.Duration // time to hold at each end
+}
+
+// DefaultMarquee is a comfortable reading speed on the OLED.
+var DefaultMarquee = Marquee{Step: 250 * time.Millisecond, Pause: time.Second}
+
+// Offset returns how many characters the text is scrolled elapsed time after
+// the marquee started, for text maxOffset characters wider than the display.
+func (m Marquee) Offset(maxOffset int, elapsed time.Duration) int {
+	if maxOffset <= 0 || m.Step <= 0 || elapsed < 0 {
+		return 0
+	}
+	travel := time.Duration(maxOffset) * m.Step
+	t := elapsed % (2 * (m.Pause + travel))
+	switch {
+	case t < m.Pause:
+		return 0
+	case t < m.Pause+travel:
+		return int((t - m.Pause) / m.Step)
+	case t < 2*m.Pause+travel:
+		return maxOffset
+	default:
+		return maxOffset - int((t-2*m.Pause-travel)/m.Step)
+	}
+}
+
+// Window returns the part of text showing elapsed time after the marquee started.
+func (m Marquee) Window(text string, width int, elapsed time.Duration) string {
+	return ScrollWindow(text, width, m.Offset(len(text)-width, elapsed))
+}
+
+// ScrollWindow returns width characters of text starting offset characters
+// in, clamped so the window never runs past either end.
+func ScrollWindow(text string, width, offset int) string {
+	if width < 0 {
+		width = 0
+	}
+	if len(text) <= width {
+		return text
+	}
+	offset = max(0, min(offset, len(text)-width))
+	return text[offset : offset+width]
+}
+
+// KnobScroll scrolls text with a knob, knob being 0 to 100 like IKnob.Value:
+// fully left shows the start of the text and fully right the end.
+func KnobScroll(text string, width, knob int) string {
+	maxOffset := len(text) - width
+	if maxOffset <= 0 {
+		return text
+	}
+	return ScrollWindow(text, width, knob*maxOffset/100)
+}
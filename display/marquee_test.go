@@ -0,0 +1,118 @@
+// Marquee (scrolling line) tests
+package display
+
+import (
+	"europi/clock"
+	"testing"
+	"time"
+)
+
+func TestMarqueeOffset(t *testing.T) {
+	m := Marquee{Step: 100 * time.Millisecond, Pause: 300 * time.Millisecond}
+	// 2 characters to scroll: hold, scroll right, hold, scroll back
+	for _, tc := range []struct {
+		at   time.Duration
+		want int
+	}{
+		{0, 0},
+		{299 * time.Millisecond, 0},
+		{300 * time.Millisecond, 0},
+		{400 * time.Millisecond, 1},
+		{500 * time.Millisecond, 2},
+		{799 * time.Millisecond, 2},
+		{800 * time.Millisecond, 2},
+		{900 * time.Millisecond, 1},
+		{1000 * time.Millisecond, 0}, // and round again
+		{1400 * time.Millisecond, 1},
+	} {
+		if got := m.Offset(2, tc.at); got != tc.want {
+			t.Errorf("At %v expected offset %d, got %d", tc.at, tc.want, got)
+		}
+	}
+	if m.Offset(0, time.Second) != 0 {
+		t.Error("Expected text that fits not to scroll")
+	}
+}
+
+func TestScrollWindow(t *testing.T) {
+	text := "0123456789ABCDEFGHIJ" // 20 chars
+	if got := ScrollWindow(text, 16, 99); got != "456789ABCDEFGHIJ" {
+		t.Errorf("Expected the window to stop at the end, got %q", got)
+	}
+	if got := ScrollWindow("short", 16, 3); got != "short" {
+		t.Errorf("Expected short text unchanged, got %q", got)
+	}
+	if got := KnobScroll(text, 16, 0); got != "0123456789ABCDEF" {
+		t.Errorf("Expected knob at 0 to show the start, got %q", got)
+	}
+	if got := KnobScroll(text, 16, 50); got != "23456789ABCDEFGH" {
+		t.Errorf("Expected knob at 50 to show the middle, got %q", got)
+	}
+	if got := KnobScroll(text, 21, 100); got != text {
+		t.Errorf("Expected TinyFont's 21 chars to fit it all, got %q", got)
+	}
+}
+
+func TestBufferedScrollingLine(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	backend := NewMockOledDevice(3, 16)
+	oled := NewBufferedDisplay(backend, 3)
+	oled.Clock = clk
+	oled.Marquee = Marquee{Step: 100 * time.Millisecond, Pause: 500 * time.Millisecond}
+
+	draw := func() {
+		oled.ClearBuffer()
+		oled.WriteLine(0, "Title")
+		oled.WriteLineScrolling(1, "0123456789ABCDEFGHIJ")
+		oled.Display()
+	}
+	draw()
+	if backend.LinesRaw[1] != "0123456789ABCDEF" {
+		t.Fatalf("Expected the start of the line first, got %q", backend.LinesRaw[1])
+	}
+	clk.Advance(700 * time.Millisecond)
+	draw() // redrawing the same text carries on scrolling rather than restarting
+	if backend.LinesRaw[1] != "23456789ABCDEFGH" {
+		t.Errorf("Expected the line to have scrolled 2 chars, got %q", backend.LinesRaw[1])
+	}
+	// Display alone animates it
+	clk.Advance(100 * time.Millisecond)
+	oled.Display()
+	if backend.LinesRaw[1] != "3456789ABCDEFGHI" || backend.LinesRaw[0] != "Title" {
+		t.Errorf("Expected Display to move the line along, got %q", backend.LinesRaw)
+	}
+	oled.WriteLine(1, "Static")
+	clk.Advance(100 * time.Millisecond)
+	oled.Display()
+	if backend.LinesRaw[1] != "Static" {
+		t.Errorf("Expected WriteLine to stop the scrolling, got %q", backend.LinesRaw[1])
+	}
+}
+
+func TestBufferedScrollingRestartsAfterClear(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	backend := NewMockOledDevice(3, 16)
+	oled := NewBufferedDisplay(backend, 3)
+	oled.Clock = clk
+	oled.Marquee = Marquee{Step: 100 * time.Millisecond, Pause: 500 * time.Millisecond}
+
+	oled.WriteLineScrolling(1, "0123456789ABCDEFGHIJ")
+	oled.Display()
+	clk.Advance(700 * time.Millisecond)
+	oled.Display()
+	if backend.LinesRaw[1] != "23456789ABCDEFGH" {
+		t.Fatalf("Expected the line to have scrolled 2 chars, got %q", backend.LinesRaw[1])
+	}
+
+	// A frame without the line, e.g. another screen, then the same text again
+	oled.ClearDisplay()
+	oled.WriteLine(0, "Other screen")
+	oled.Display()
+	clk.Advance(300 * time.Millisecond)
+	oled.ClearDisplay()
+	oled.WriteLineScrolling(1, "0123456789ABCDEFGHIJ")
+	oled.Display()
+	if backend.LinesRaw[1] != "0123456789ABCDEF" {
+		t.Errorf("Expected the line to start again from the beginning, got %q", backend.LinesRaw[1])
+	}
+}
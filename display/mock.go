@@ -8,37 +8,86 @@ import "bytes"
 type MockOledDevice struct {
 	LinesRaw []string // like a real OLED, but in memory
 	LineLen  int      // max chars per line (16 for 8x8, 21 for TinyFont)
-	numLines int      // number of lines (3 or 4)
+	numLines int      // number of lines (1 to 4)
+	layout   Layout
+	fb       *Framebuffer
+	text     *SSD1306Adapter8x8 // renders the lines into fb, as the OLED would
+	// SnapshotDir, if set, gets a PNG of every frame shown, frame-00001.png
+	// onwards. For a one off, use Framebuffer().SavePNG.
+	SnapshotDir string
+	snapshots   int
 }
 
-// GetSSD1306 returns nil for the mock device.
-// Its really a *ssd1306.Device but we return nil here since it's a mock
+// GetSSD1306 returns the mock's pixel framebuffer, a *Framebuffer.
+// On hardware its really a *ssd1306.Device
 func (m *MockOledDevice) GetSSD1306() any {
-	return nil
+	return m.fb
+}
+
+// Framebuffer returns the pixels drawn by apps through GetSSD1306, along with
+// the lines of text rendered in the 8x8 font.
+func (m *MockOledDevice) Framebuffer() *Framebuffer {
+	return m.fb
 }
 
 func NewMockOledDevice(numLines, lineLen int) *MockOledDevice {
 	m := &MockOledDevice{LineLen: lineLen}
+	m.fb = NewFramebuffer(EuroPiPanel.Width, EuroPiPanel.Height)
+	m.fb.OnDisplay = func(fb *Framebuffer) {
+		print(PixelString(fb))
+		m.snapshot()
+	}
 	m.SetNumLines(numLines)
 	return m
 }
 
 func (m *MockOledDevice) SetNumLines(numLines int) {
-	if numLines < 3 || numLines > 4 {
-		panic("numLines must be 3 or 4")
+	m.SetLayout(LayoutForLines(numLines))
+}
+
+// SetLayout switches layout. Large lines hold fewer characters, and the
+// framebuffer shows them at their real size.
+func (m *MockOledDevice) SetLayout(layout Layout) {
+	m.numLines = layout.NumLines()
+	m.LinesRaw = make([]string, m.numLines) // reset lines to empty
+	if m.text == nil {
+		m.text = NewAdapter8x8(m.fb, 3)
 	}
-	m.numLines = numLines
-	m.LinesRaw = make([]string, numLines) // reset lines to empty
+	m.text.SetLayout(layout)
+	m.layout = m.text.Layout() // positioned for the framebuffer
+}
+
+func (m *MockOledDevice) Layout() Layout {
+	return m.layout
 }
 
 func (m *MockOledDevice) NumLines() int {
 	return m.numLines
 }
 
+func (m *MockOledDevice) CharsPerLine() int {
+	return m.LineLen
+}
+
+func (m *MockOledDevice) CharsOnLine(lineNum int) int {
+	return m.LineLen / int(m.layout.Scale(lineNum))
+}
+
+func (m *MockOledDevice) Size() (width, height int16) {
+	return m.fb.Size()
+}
+
+// TextWidth measures text as if each of the LineLen characters had an equal
+// share of the width, 8 pixels for the 8x8 font or 6 for TinyFont.
+func (m *MockOledDevice) TextWidth(text string) int16 {
+	return mockTextWidth(m.fb, m.LineLen, text)
+}
+
 func (m *MockOledDevice) ClearDisplay() {
 	for i := range m.LinesRaw {
 		m.LinesRaw[i] = ""
 	}
+	m.fb.ClearBuffer()
 }
 
 func (m *MockOledDevice) ClearBuffer() {
@@ -50,16 +99,18 @@ func (m *MockOledDevice) WriteLine(lineNum int, text string) {
 	if lineNum < 0 || lineNum >= len(m.LinesRaw) {
 		return // ignore out of range
 	}
+	m.text.WriteLine(lineNum, text)
 	// Truncate text to max line length
-	if len(text) > m.LineLen {
-		text = text[:m.LineLen]
+	if maxLen := m.LineLen / int(m.layout.Scale(lineNum)); len(text) > maxLen {
+		text = text[:maxLen]
 	}
 	m.LinesRaw[lineNum] = text
 }
 
 func (m *MockOledDevice) WriteLineHighlighted(lineNum int, text string) {
+	m.text.WriteLineHighlighted(lineNum, text)
 	marker := " *"
-	maxTextLen := m.LineLen - len(marker)
+	maxTextLen := m.LineLen/int(m.layout.Scale(lineNum)) - len(marker)
 	if maxTextLen < 0 {
 		maxTextLen = 0
 	}
@@ -69,6 +120,19 @@ func (m *MockOledDevice) WriteLineHighlighted(lineNum int, text string) {
 	m.LinesRaw[lineNum] = text + marker
 }
 
+// WriteLineSpans renders the spans into the framebuffer and marks them in
+// the text with [inverted], _underlined_ and |c|ursor, see spanMarkers.
+func (m *MockOledDevice) WriteLineSpans(lineNum int, text string, spans ...Span) {
+	if lineNum < 0 || lineNum >= len(m.LinesRaw) {
+		return // ignore out of range
+	}
+	m.text.WriteLineSpans(lineNum, text, spans...)
+	if maxLen := m.LineLen / int(m.layout.Scale(lineNum)); len(text) > maxLen {
+		text = text[:maxLen]
+	}
+	m.LinesRaw[lineNum] = markSpans(text, spans, spanMarkers)
+}
+
 func (m *MockOledDevice) DisplayString() string {
 	const width = 25
 	top := "┌" + string(bytes.Repeat([]byte("─"), width)) + "┐"
@@ -84,6 +148,57 @@ func (m *MockOledDevice) DisplayString() string {
 
 func (m *MockOledDevice) Display() {
 	print(m.DisplayString())
+	m.snapshot()
+}
+
+func (m *MockOledDevice) snapshot() {
+	if m.SnapshotDir != "" {
+		m.snapshots++
+		saveSnapshot(m.SnapshotDir, m.snapshots, m.fb)
+	}
+}
+
+// spanMarkers shows spans in plain text. Through a BufferedDisplay a cursor
+// arrives as an inverted span while it's showing; written directly it is
+// always shown.
+func spanMarkers(s Span) (before, after string) {
+	switch s.Style {
+	case SpanUnderline:
+		return "_", "_"
+	case SpanCursor:
+		return "|", "|"
+	}
+	return "[", "]"
+}
+
+func mockTextWidth(fb *Framebuffer, lineLen int, text string) int16 {
+	if lineLen <= 0 {
+		return 0
+	}
+	width, _ := fb.Size()
+	return int16(len(text)) * (width / int16(lineLen))
+}
+
+// PixelString draws the framebuffer in a box, with half blocks for the pixels.
+func PixelString(fb *Framebuffer) string {
+	return pixelBox(fb.HalfBlocks()) + "\n"
+}
+
+// pixelBox draws rows of half blocks (see Framebuffer.HalfBlocks) in a box.
+func pixelBox(rows []string) string {
+	width := 0
+	if len(rows) > 0 {
+		width = len([]rune(rows[0]))
+	}
+	top := "┌" + string(bytes.Repeat([]byte("─"), width)) + "┐"
+	bottom := "└" + string(bytes.Repeat([]byte("─"), width)) + "┘"
+	var out bytes.Buffer
+	out.WriteString(top + "\n")
+	for _, row := range rows {
+		out.WriteString("│" + row + "│\n")
+	}
+	out.WriteString(bottom)
+	return out.String()
 }
 
 func padOrTruncate(s string, n int) string {
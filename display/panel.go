@@ -0,0 +1,56 @@
+package display
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PanelConfig describes how the SSD1306 OLED is wired up and its size.
+// Pins are RP2040 GPIO numbers.
+type PanelConfig struct {
+	I2CBus    int // 0 for I2C0, 1 for I2C1
+	SDA, SCL  int
+	Frequency uint32 // I2C clock in Hz
+	Address   uint16
+	Width     int16
+	Height    int16
+}
+
+// EuroPiPanel is the 128x32 OLED of the original EuroPi, on I2C0 (GP0/GP1) at 0x3C.
+var EuroPiPanel = PanelConfig{
+	I2CBus:    0,
+	SDA:       0,
+	SCL:       1,
+	Frequency: 400000,
+	Address:   0x3C,
+	Width:     128,
+	Height:    32,
+}
+
+// Validate checks the panel against what the RP2040 and SSD1306 support.
+func (p PanelConfig) Validate() error {
+	if p.I2CBus != 0 && p.I2CBus != 1 {
+		return fmt.Errorf("display: no I2C bus %d", p.I2CBus)
+	}
+	// On the RP2040 each I2C bus is available on every 4th pair of pins,
+	// SDA on the even pin and SCL on the odd one.
+	if p.SDA < 0 || p.SDA > 29 || p.SDA%2 != 0 || (p.SDA/2)%2 != p.I2CBus {
+		return fmt.Errorf("display: GPIO%d can't be SDA for I2C%d", p.SDA, p.I2CBus)
+	}
+	if p.SCL < 0 || p.SCL > 29 || p.SCL%2 != 1 || (p.SCL/2)%2 != p.I2CBus {
+		return fmt.Errorf("display: GPIO%d can't be SCL for I2C%d", p.SCL, p.I2CBus)
+	}
+	if p.Frequency == 0 || p.Frequency > 1000000 {
+		return fmt.Errorf("display: unsupported I2C frequency %d", p.Frequency)
+	}
+	if p.Address != 0x3C && p.Address != 0x3D {
+		return fmt.Errorf("display: SSD1306 address must be 0x3C or 0x3D, not 0x%X", p.Address)
+	}
+	if p.Width <= 0 || p.Width > 128 {
+		return errors.New("display: width must be 1..128")
+	}
+	if p.Height != 32 && p.Height != 64 {
+		return errors.New("display: height must be 32 or 64")
+	}
+	return nil
+}
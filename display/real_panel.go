@@ -0,0 +1,32 @@
+//go:build tinygo
+
+package display
+
+import (
+	"machine"
+
+	"tinygo.org/x/drivers/ssd1306"
+)
+
+// newSSD1306 configures the I2C bus and the SSD1306 described by panel.
+func newSSD1306(panel PanelConfig) ssd1306.Device {
+	if err := panel.Validate(); err != nil {
+		panic(err.Error())
+	}
+	i2c := machine.I2C0
+	if panel.I2CBus == 1 {
+		i2c = machine.I2C1
+	}
+	i2c.Configure(machine.I2CConfig{
+		Frequency: panel.Frequency,
+		SDA:       machine.Pin(panel.SDA),
+		SCL:       machine.Pin(panel.SCL),
+	})
+	dev := ssd1306.NewI2C(i2c)
+	dev.Configure(ssd1306.Config{
+		Address: panel.Address,
+		Width:   panel.Width,
+		Height:  panel.Height,
+	})
+	return dev
+}
@@ -4,8 +4,6 @@
 package display
 
 import (
-	"machine"
-
 	"tinygo.org/x/drivers/ssd1306"
 	"tinygo.org/x/tinyfont"
 	"tinygo.org/x/tinyfont/proggy"
@@ -15,7 +13,8 @@ type SSD1306Adapter struct {
 	dev ssd1306.Device
 	// lineYs holds the Y positions for each line in 3-line or 4-line mode, coord is the top of the font, drawn to bottom
 	lineYs   []int16
-	numLines int // Number of lines (3 or 4)
+	numLines int // Number of lines (1 to 4)
+	layout   Layout
 }
 
 // GetSSD1306 returns the underlying SSD1306 device.
@@ -36,24 +35,65 @@ func (o *SSD1306Adapter) Display() {
 	o.dev.Display()
 }
 
-// SetNumLines sets the number of lines for the display (3 or 4).
+// SetNumLines sets the number of lines for the display (1 to 4, see LayoutForLines).
 func (o *SSD1306Adapter) SetNumLines(numLines int) {
-	if numLines < 3 || numLines > 4 {
-		panic("numLines must be 3 or 4")
+	o.SetLayout(LayoutForLines(numLines))
+}
+
+// SetLayout sets the line positions. TinyFont can't be scaled, so large
+// lines are drawn in the 8x8 font scaled up instead.
+func (o *SSD1306Adapter) SetLayout(layout Layout) {
+	_, height := o.dev.Size()
+	layout = layout.ForHeight(height)
+	o.layout = layout
+	o.numLines = layout.NumLines()
+	// The baseline is 7 pixels below the top of the line, as in 4 line mode.
+	// TinyFont is 10 pixels high, so in 3 line mode it goes one lower to use the gaps.
+	baseline := int16(7)
+	if layout.Name == Layout3Lines.Name {
+		baseline = 8
 	}
-	if numLines == 3 {
-		o.lineYs = []int16{10, 20, 30} // y pixel coordinate of the BOTTOM of each character (for tinyfont mode)
-	} else {
-		o.lineYs = []int16{7, 15, 23, 31}
+	o.lineYs = make([]int16, layout.NumLines())
+	for i, line := range layout.Lines {
+		o.lineYs[i] = line.Y + baseline
 	}
+}
 
-	o.numLines = numLines
+func (o *SSD1306Adapter) Layout() Layout {
+	return o.layout
 }
 
 func (o *SSD1306Adapter) NumLines() int {
 	return o.numLines
 }
 
+// CharsPerLine is 21 on a 128 pixel wide display, proggy TinySZ8pt7b being 6 pixels wide
+func (o *SSD1306Adapter) CharsPerLine() int {
+	width, _ := o.dev.Size()
+	return int(width) / 6
+}
+
+// CharsOnLine is CharsPerLine on normal lines. Large lines are drawn in the
+// 8x8 font scaled up, so they fit 8 characters at 2x and 5 at 3x.
+func (o *SSD1306Adapter) CharsOnLine(lineNum int) int {
+	scale := o.layout.Scale(lineNum)
+	if scale == 1 {
+		return o.CharsPerLine()
+	}
+	width, _ := o.dev.Size()
+	return int(width / (8 * scale))
+}
+
+func (o *SSD1306Adapter) Size() (width, height int16) {
+	return o.dev.Size()
+}
+
+// TextWidth measures text in TinyFont, which unlike the 8x8 font is proportional
+func (o *SSD1306Adapter) TextWidth(text string) int16 {
+	_, width := tinyfont.LineWidth(&proggy.TinySZ8pt7b, text)
+	return int16(width)
+}
+
 /*
 Coordinates. The top left corner is (0, 0).
 - X ranges from 0 to 127 (left to right)
@@ -120,6 +160,10 @@ func (o *SSD1306Adapter) WriteLine(lineNum int, text string) {
 	// clearH := int16(10) // Height of the line to clear (10 pixels)
 	// fillRectSafe(o.dev, 0, clearY, int16(128), clearH, ColorBlack)
 
+	if scale := o.layout.Scale(lineNum); scale > 1 {
+		DrawFont8x8TextScaled(&o.dev, 0, o.layout.Lines[lineNum].Y, text, scale, ColorWhite)
+		return
+	}
 	tinyfont.WriteLine(&o.dev, &proggy.TinySZ8pt7b, 0, y, text, ColorWhite)
 
 	// if len(text) >= 2 && text[len(text)-2:] == " *" {
@@ -137,22 +181,42 @@ func (o *SSD1306Adapter) WriteLineHighlighted(lineNum int, text string) {
 	o.WriteLine(lineNum, text)
 }
 
+// WriteLineSpans writes a line with ranges of characters inverted or
+// underlined, cursors being drawn inverted (BufferedDisplay blinks them).
+// TinyFont is proportional, so the spans are measured with tinyfont.LineWidth.
+func (o *SSD1306Adapter) WriteLineSpans(lineNum int, text string, spans ...Span) {
+	if lineNum < 0 || lineNum >= len(o.lineYs) {
+		return
+	}
+	o.WriteLine(lineNum, text)
+	if scale := o.layout.Scale(lineNum); scale > 1 {
+		drawSpans8x8(&o.dev, o.layout.Lines[lineNum].Y, scale, o.layout.HighlightMargin, o.layout.HighlightMargin, text, spans)
+		return
+	}
+	baseline := o.lineYs[lineNum]
+	for _, s := range clipSpans(text, spans) {
+		_, x := tinyfont.LineWidth(&proggy.TinySZ8pt7b, text[:s.Start])
+		_, w := tinyfont.LineWidth(&proggy.TinySZ8pt7b, text[s.Start:s.End])
+		switch s.Style {
+		case SpanUnderline:
+			fillRectSafe(&o.dev, int16(x), baseline+1, int16(w), 1, ColorWhite)
+		case SpanInvert, SpanCursor:
+			fillRectSafe(&o.dev, int16(x), baseline-7, int16(w), 8, ColorWhite)
+			tinyfont.WriteLine(&o.dev, &proggy.TinySZ8pt7b, int16(x), baseline, text[s.Start:s.End], ColorBlack)
+		}
+	}
+}
+
 // NewOledDeviceTinyFont sets up the I2C and SSD1306 display and returns the display instance.
 func NewOledDeviceTinyFont(numLines int) IOledDevice {
-	i2c := machine.I2C0
-	i2c.Configure(machine.I2CConfig{
-		Frequency: 400000,
-		SDA:       machine.GP0,
-		SCL:       machine.GP1,
-	})
-	dev := ssd1306.NewI2C(i2c)
-	dev.Configure(ssd1306.Config{
-		Address: 0x3C,
-		Width:   128,
-		Height:  32,
-	})
+	return NewOledDeviceTinyFontWithPanel(EuroPiPanel, numLines)
+}
+
+// NewOledDeviceTinyFontWithPanel is NewOledDeviceTinyFont for a panel wired or
+// sized differently, e.g. HardwareProfile.Panel
+func NewOledDeviceTinyFontWithPanel(panel PanelConfig, numLines int) IOledDevice {
 	adapter := &SSD1306Adapter{
-		dev: dev,
+		dev: newSSD1306(panel),
 	}
 	adapter.SetNumLines(numLines)
 	return adapter
@@ -0,0 +1,77 @@
+package display
+
+import (
+	"europi/clock"
+	"sort"
+	"time"
+)
+
+// SpanStyle is how a Span of characters stands out from the rest of the line.
+type SpanStyle int
+
+const (
+	// SpanInvert draws the characters dark on a lit background, like WriteLineHighlighted.
+	SpanInvert SpanStyle = iota
+	// SpanUnderline draws a line under the characters.
+	SpanUnderline
+	// SpanCursor is a blinking inverted block, e.g. the character being edited.
+	SpanCursor
+)
+
+// Span marks characters Start up to (not including) End of a line for WriteLineSpans.
+type Span struct {
+	Start, End int
+	Style      SpanStyle
+}
+
+// Invert returns a span inverting characters start to end, e.g. the selected field.
+func Invert(start, end int) Span { return Span{start, end, SpanInvert} }
+
+// Underline returns a span underlining characters start to end.
+func Underline(start, end int) Span { return Span{start, end, SpanUnderline} }
+
+// Cursor returns a blinking cursor on the character at pos.
+func Cursor(pos int) Span { return Span{pos, pos + 1, SpanCursor} }
+
+// CursorBlink is how long a cursor stays on, then off.
+const CursorBlink = 400 * time.Millisecond
+
+// CursorVisible reports whether blinking cursors are showing at the moment.
+// Keep redrawing, e.g. every 100ms, while a cursor is on screen to make it blink.
+func CursorVisible(clk clock.Clock) bool {
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	// UnixMilli rather than UnixNano, which overflows for the zero time virtual clocks start at
+	return clk.Now().UnixMilli()/CursorBlink.Milliseconds()%2 == 0
+}
+
+// clipSpans returns the spans within text, in order, dropping empty ones.
+func clipSpans(text string, spans []Span) []Span {
+	var clipped []Span
+	for _, s := range spans {
+		s.Start = max(0, s.Start)
+		s.End = min(len(text), s.End)
+		if s.Start < s.End {
+			clipped = append(clipped, s)
+		}
+	}
+	sort.SliceStable(clipped, func(i, j int) bool { return clipped[i].Start < clipped[j].Start })
+	return clipped
+}
+
+// markSpans wraps each span of text in the strings returned by mark, for
+// showing spans in the mock displays. Overlapping spans are skipped.
+func markSpans(text string, spans []Span, mark func(s Span) (before, after string)) string {
+	out := ""
+	pos := 0
+	for _, s := range clipSpans(text, spans) {
+		if s.Start < pos {
+			continue
+		}
+		before, after := mark(s)
+		out += text[pos:s.Start] + before + text[s.Start:s.End] + after
+		pos = s.End
+	}
+	return out + text[pos:]
+}
@@ -0,0 +1,60 @@
+// Span (partial highlighting and cursor) tests
+package display
+
+import (
+	"europi/clock"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClipSpans(t *testing.T) {
+	got := clipSpans("hello", []Span{Underline(3, 9), Invert(-2, 1), Cursor(5), Invert(2, 2)})
+	want := []Span{Invert(0, 1), Underline(3, 5)}
+	if !spansEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestMockMarksSpans(t *testing.T) {
+	oled := NewMockOledDevice(3, 16)
+	oled.WriteLineSpans(0, " 1.0x  2.0x ", Invert(6, 12))
+	oled.WriteLineSpans(1, "BPM 120", Underline(4, 7), Cursor(0))
+	oled.WriteLineSpans(2, "overlap", Invert(0, 4), Underline(2, 6))
+	for i, want := range []string{" 1.0x [ 2.0x ]", "|B|PM _120_", "[over]lap"} {
+		if oled.LinesRaw[i] != want {
+			t.Errorf("Line %d: expected %q, got %q", i, want, oled.LinesRaw[i])
+		}
+	}
+}
+
+func TestCursorBlinks(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	oled := NewMockOledDevice(3, 16)
+	buffered := NewBufferedDisplay(oled, 3)
+	buffered.Clock = clk
+
+	buffered.WriteLineSpans(0, "Name", Cursor(2))
+	if got := buffered.DisplayString(); !strings.Contains(got, "Na[m]e") {
+		t.Errorf("Expected the cursor to show, got:\n%s", got)
+	}
+	if got := buffered.DisplayString(); got != "" {
+		t.Errorf("Expected no redraw until the cursor blinks, got:\n%s", got)
+	}
+	clk.Advance(CursorBlink)
+	if got := buffered.DisplayString(); !strings.Contains(got, "│Name ") {
+		t.Errorf("Expected the cursor to blink off, got:\n%s", got)
+	}
+	clk.Advance(CursorBlink)
+	if got := buffered.DisplayString(); !strings.Contains(got, "Na[m]e") {
+		t.Errorf("Expected the cursor to blink back on, got:\n%s", got)
+	}
+
+	// Plain lines don't redraw as the cursor blinks
+	buffered.WriteLine(0, "Name")
+	buffered.DisplayString()
+	clk.Advance(CursorBlink)
+	if got := buffered.DisplayString(); got != "" {
+		t.Errorf("Expected no redraw without a cursor, got:\n%s", got)
+	}
+}
@@ -13,19 +13,40 @@ const HighlightSymbol = " *"
 
 type MockOledDeviceTea struct {
 	LinesRaw []string // like a real OLED, but in memory
+	spans    [][]Span // per line, from WriteLineSpans
 	program  *tea.Program
 	LineLen  int // max chars per line (16 for 8x8, 21 for TinyFont)
-	numLines int // number of lines (3 or 4)
+	numLines int // number of lines (1 to 4)
+	layout   Layout
+	fb       *Framebuffer
+	text     *SSD1306Adapter8x8 // renders the lines into fb, as the OLED would
+	// SnapshotDir, if set, gets a PNG of every frame shown (see MockOledDevice)
+	SnapshotDir string
+	snapshots   int
 }
 
-// GetSSD1306 returns nil for the mock Bubble Tea device.
-// Its really a *ssd1306.Device but we return nil here since it's a mock
+// GetSSD1306 returns the mock's pixel framebuffer, a *Framebuffer.
+// On hardware its really a *ssd1306.Device
 func (m *MockOledDeviceTea) GetSSD1306() any {
-	return nil
+	return m.fb
+}
+
+// Framebuffer returns the pixels drawn by apps through GetSSD1306, along with
+// the lines of text rendered in the 8x8 font.
+func (m *MockOledDeviceTea) Framebuffer() *Framebuffer {
+	return m.fb
 }
 
 func NewMockOledDeviceTea(numLines, lineLen int) *MockOledDeviceTea {
 	m := &MockOledDeviceTea{LineLen: lineLen}
+	m.fb = NewFramebuffer(EuroPiPanel.Width, EuroPiPanel.Height)
+	// Pixel frames replace the text view until the next line of text is written
+	m.fb.OnDisplay = func(fb *Framebuffer) {
+		if m.program != nil {
+			m.program.Send(pixelsMsg{rows: fb.HalfBlocks()})
+		}
+		m.snapshot()
+	}
 	m.SetNumLines(numLines)
 	// Use AltScreen for proper terminal cleanup
 	m.program = tea.NewProgram(
@@ -43,22 +64,55 @@ func NewMockOledDeviceTea(numLines, lineLen int) *MockOledDeviceTea {
 }
 
 func (m *MockOledDeviceTea) SetNumLines(numLines int) {
-	if numLines < 3 || numLines > 4 {
-		panic("numLines must be 3 or 4")
+	m.SetLayout(LayoutForLines(numLines))
+}
+
+// SetLayout switches layout. Large lines hold fewer characters, and the
+// framebuffer shows them at their real size.
+func (m *MockOledDeviceTea) SetLayout(layout Layout) {
+	m.numLines = layout.NumLines()
+	m.LinesRaw = make([]string, m.numLines) // reset lines to empty
+	m.spans = make([][]Span, m.numLines)
+	if m.text == nil {
+		m.text = NewAdapter8x8(m.fb, 3)
 	}
-	m.numLines = numLines
-	m.LinesRaw = make([]string, numLines) // reset lines to empty
+	m.text.SetLayout(layout)
+	m.layout = m.text.Layout() // positioned for the framebuffer
 	m.update()
 }
 
+func (m *MockOledDeviceTea) Layout() Layout {
+	return m.layout
+}
+
 func (m *MockOledDeviceTea) NumLines() int {
 	return m.numLines
 }
 
+func (m *MockOledDeviceTea) CharsPerLine() int {
+	return m.LineLen
+}
+
+func (m *MockOledDeviceTea) CharsOnLine(lineNum int) int {
+	return m.LineLen / int(m.layout.Scale(lineNum))
+}
+
+func (m *MockOledDeviceTea) Size() (width, height int16) {
+	return m.fb.Size()
+}
+
+// TextWidth measures text as if each of the LineLen characters had an equal
+// share of the width, 8 pixels for the 8x8 font or 6 for TinyFont.
+func (m *MockOledDeviceTea) TextWidth(text string) int16 {
+	return mockTextWidth(m.fb, m.LineLen, text)
+}
+
 func (m *MockOledDeviceTea) ClearDisplay() {
 	for i := range m.LinesRaw {
 		m.LinesRaw[i] = ""
+		m.spans[i] = nil
 	}
+	m.fb.ClearBuffer()
 	m.update()
 }
 
@@ -71,11 +125,13 @@ func (m *MockOledDeviceTea) WriteLine(lineNum int, text string) {
 	if lineNum < 0 || lineNum >= len(m.LinesRaw) {
 		return // ignore out of range
 	}
+	m.text.WriteLine(lineNum, text)
 	// Truncate text to max line length
-	if len(text) > m.LineLen {
-		text = text[:m.LineLen]
+	if maxLen := m.LineLen / int(m.layout.Scale(lineNum)); len(text) > maxLen {
+		text = text[:maxLen]
 	}
 	m.LinesRaw[lineNum] = text
+	m.spans[lineNum] = nil
 	m.update()
 }
 
@@ -83,8 +139,9 @@ func (m *MockOledDeviceTea) WriteLineHighlighted(lineNum int, text string) {
 	if lineNum < 0 || lineNum >= len(m.LinesRaw) {
 		return // ignore out of range
 	}
+	m.text.WriteLineHighlighted(lineNum, text)
 	marker := HighlightSymbol
-	maxTextLen := m.LineLen - len(marker)
+	maxTextLen := m.LineLen/int(m.layout.Scale(lineNum)) - len(marker)
 	if maxTextLen < 0 {
 		maxTextLen = 0
 	}
@@ -92,11 +149,28 @@ func (m *MockOledDeviceTea) WriteLineHighlighted(lineNum int, text string) {
 		text = text[:maxTextLen]
 	}
 	m.LinesRaw[lineNum] = text + marker
+	m.spans[lineNum] = nil
+	m.update()
+}
+
+// WriteLineSpans renders the spans into the framebuffer, and in the terminal
+// shows them in reverse video, underlined or as a blinking block.
+func (m *MockOledDeviceTea) WriteLineSpans(lineNum int, text string, spans ...Span) {
+	if lineNum < 0 || lineNum >= len(m.LinesRaw) {
+		return // ignore out of range
+	}
+	m.text.WriteLineSpans(lineNum, text, spans...)
+	if maxLen := m.LineLen / int(m.layout.Scale(lineNum)); len(text) > maxLen {
+		text = text[:maxLen]
+	}
+	m.LinesRaw[lineNum] = text
+	m.spans[lineNum] = spans
 	m.update()
 }
 
 func (m *MockOledDeviceTea) Display() {
 	m.update()
+	m.snapshot()
 	logutil.Println(m.DisplayString())
 }
 
@@ -106,28 +180,44 @@ func (m *MockOledDeviceTea) DisplayString() string {
 	bottom := "└" + string(bytes.Repeat([]byte("─"), width)) + "┘"
 	var out bytes.Buffer
 	out.WriteString(top + "\n")
-	for _, line := range m.LinesRaw {
-		out.WriteString("│" + padOrTruncateTea(line, width) + "│\n")
+	for i, line := range m.LinesRaw {
+		out.WriteString("│" + padOrTruncateTea(markSpans(line, m.spans[i], spanMarkers), width) + "│\n")
 	}
 	out.WriteString(bottom + "\n")
 	return out.String()
 }
 
+func (m *MockOledDeviceTea) snapshot() {
+	if m.SnapshotDir != "" {
+		m.snapshots++
+		saveSnapshot(m.SnapshotDir, m.snapshots, m.fb)
+	}
+}
+
 func (m *MockOledDeviceTea) update() {
 	if m.program != nil {
 		// Send a copy of the lines slice to avoid race conditions
 		linesCopy := make([]string, len(m.LinesRaw))
 		copy(linesCopy, m.LinesRaw)
-		m.program.Send(updateMsg{lines: linesCopy})
+		spansCopy := make([][]Span, len(m.spans))
+		copy(spansCopy, m.spans)
+		m.program.Send(updateMsg{lines: linesCopy, spans: spansCopy})
 	}
 }
 
 type updateMsg struct {
 	lines []string
+	spans [][]Span
+}
+
+type pixelsMsg struct {
+	rows []string
 }
 
 type oledModel struct {
-	lines []string
+	lines  []string
+	spans  [][]Span
+	pixels []string // half block rows of the last pixel frame, nil when showing text
 }
 
 func (m *oledModel) Init() tea.Cmd {
@@ -142,24 +232,47 @@ func (m *oledModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case updateMsg:
 		m.lines = msg.lines
+		m.spans = msg.spans
+		m.pixels = nil
+	case pixelsMsg:
+		m.pixels = msg.rows
 	}
 	return m, nil
 }
 
 func (m *oledModel) View() string {
+	if m.pixels != nil {
+		return pixelBox(m.pixels)
+	}
 	// Fixed width for border
 	const width = 25
 	top := "┌" + string(bytes.Repeat([]byte("─"), width)) + "┐"
 	bottom := "└" + string(bytes.Repeat([]byte("─"), width)) + "┘"
 	var b bytes.Buffer
 	b.WriteString(top + "\n")
-	for _, line := range m.lines {
-		b.WriteString("│" + padOrTruncateTea(line, width) + "│\n")
+	for i, line := range m.lines {
+		line = padOrTruncateTea(line, width)
+		if i < len(m.spans) {
+			line = markSpans(line, m.spans[i], ansiSpan)
+		}
+		b.WriteString("│" + line + "│\n")
 	}
 	b.WriteString(bottom)
 	return b.String()
 }
 
+// ansiSpan styles a span with terminal escape codes
+func ansiSpan(s Span) (before, after string) {
+	const reset = "\x1b[0m"
+	switch s.Style {
+	case SpanUnderline:
+		return "\x1b[4m", reset
+	case SpanCursor:
+		return "\x1b[5;7m", reset
+	}
+	return "\x1b[7m", reset
+}
+
 func padOrTruncateTea(s string, n int) string {
 	if len(s) > n {
 		return s[:n]
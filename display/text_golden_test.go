@@ -0,0 +1,142 @@
+// Golden image tests for the 8x8 text renderer. These live in display_test
+// because displaytest imports display.
+package display_test
+
+import (
+	"europi/clock"
+	"europi/display"
+	"europi/display/displaytest"
+	"testing"
+	"time"
+)
+
+func TestText8x8Golden(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		numLines int
+	}{
+		{"text-3-lines", 3},
+		{"text-4-lines", 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fb := display.NewFramebuffer(display.EuroPiPanel.Width, display.EuroPiPanel.Height)
+			oled := display.NewAdapter8x8(fb, tc.numLines)
+			oled.WriteLine(0, "--- MENU ---")
+			oled.WriteLineHighlighted(1, "Highlighted")
+			oled.WriteLine(2, "Clipped at 16 chars!")
+			oled.WriteLine(3, "Line 4 {}[]~")
+			displaytest.AssertGolden(t, fb, tc.name)
+		})
+	}
+}
+
+func TestMockRendersText(t *testing.T) {
+	oled := display.NewMockOledDevice(3, 16)
+	oled.WriteLine(0, "--- MENU ---")
+	oled.WriteLineHighlighted(1, "Highlighted")
+	oled.WriteLine(2, "Clipped at 16 chars!")
+
+	// The mock draws exactly what the OLED adapter does
+	want := display.NewFramebuffer(display.EuroPiPanel.Width, display.EuroPiPanel.Height)
+	adapter := display.NewAdapter8x8(want, 3)
+	adapter.WriteLine(0, "--- MENU ---")
+	adapter.WriteLineHighlighted(1, "Highlighted")
+	adapter.WriteLine(2, "Clipped at 16 chars!")
+	if diff := displaytest.Diff(want, oled.Framebuffer()); diff != "" {
+		t.Errorf("Expected the mock to render text like the OLED:\n%s", diff)
+	}
+	displaytest.AssertGolden(t, oled.Framebuffer(), "text-3-lines-mock")
+}
+
+func TestLayoutsGolden(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		layout display.Layout
+		lines  []string
+	}{
+		{"layout-big-digits", display.LayoutBigDigits, []string{"120.5"}},
+		{"layout-2-lines", display.Layout2Lines, []string{"BPM 120", "Sync DIN"}},
+		{"layout-large-with-status", display.LayoutLargeWithStatus, []string{"-4.95V", "CV1 slew 30ms"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			oled := display.NewMockOledDevice(3, 16)
+			oled.SetLayout(tc.layout)
+			for i, line := range tc.lines {
+				oled.WriteLine(i, line)
+			}
+			displaytest.AssertGolden(t, oled.Framebuffer(), tc.name)
+		})
+	}
+}
+
+// A 128x64 panel, like on EuroPi X style builds, spreads the lines out
+func TestLayouts64Golden(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		layout display.Layout
+		lines  []string
+	}{
+		{"layout-3-lines-64", display.Layout3Lines, []string{"--- MENU ---", "Hello World", "Pulse Sync"}},
+		{"layout-large-with-status-64", display.LayoutLargeWithStatus, []string{"-4.95V", "CV1 slew 30ms"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fb := display.NewFramebuffer(128, 64)
+			oled := display.NewAdapter8x8(fb, 3)
+			oled.SetLayout(tc.layout)
+			for i, line := range tc.lines {
+				oled.WriteLine(i, line)
+			}
+			displaytest.AssertGolden(t, fb, tc.name)
+		})
+	}
+}
+
+func TestSpansGolden(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		layout display.Layout
+	}{
+		{"spans-3-lines", display.Layout3Lines},
+		{"spans-4-lines", display.Layout4Lines},
+		{"spans-2-lines", display.Layout2Lines},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fb := display.NewFramebuffer(display.EuroPiPanel.Width, display.EuroPiPanel.Height)
+			oled := display.NewAdapter8x8(fb, 3)
+			oled.SetLayout(tc.layout)
+			oled.WriteLineSpans(0, " 1.0x  2.0x ", display.Invert(6, 12))
+			oled.WriteLineSpans(1, "BPM 120", display.Underline(4, 7))
+			oled.WriteLineSpans(2, "Clipped spans ok", display.Invert(14, 20))
+			oled.WriteLineSpans(3, "Line 4", display.Underline(0, 4))
+			displaytest.AssertGolden(t, fb, tc.name)
+		})
+	}
+}
+
+// The cursor blinks on the BufferedDisplay's clock, not the wall clock
+func TestCursorBlinkGolden(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	oled := display.NewMockOledDevice(3, 16)
+	buffered := display.NewBufferedDisplay(oled, 3)
+	buffered.Clock = clk
+	frame := func(write func(d display.IOledDevice)) *display.Framebuffer {
+		want := display.NewMockOledDevice(3, 16)
+		write(want)
+		return want.Framebuffer()
+	}
+
+	buffered.WriteLineSpans(1, "Name", display.Cursor(2))
+	buffered.Display()
+	displaytest.AssertGolden(t, oled.Framebuffer(), "cursor-on")
+	on := frame(func(d display.IOledDevice) { d.WriteLineSpans(1, "Name", display.Invert(2, 3)) })
+	if diff := displaytest.Diff(on, oled.Framebuffer()); diff != "" {
+		t.Errorf("Expected the cursor drawn inverted:\n%s", diff)
+	}
+
+	clk.Advance(display.CursorBlink)
+	buffered.Display()
+	off := frame(func(d display.IOledDevice) { d.WriteLine(1, "Name") })
+	if diff := displaytest.Diff(off, oled.Framebuffer()); diff != "" {
+		t.Errorf("Expected the cursor blinked off:\n%s", diff)
+	}
+}
@@ -0,0 +1,36 @@
+package display
+
+import "strings"
+
+// EllipsisText ends lines cut short by Ellipsis.
+const EllipsisText = "..."
+
+// Fits reports whether text fits on one line of the display.
+func Fits(d IOledDevice, text string) bool {
+	width, _ := d.Size()
+	return d.TextWidth(text) <= width
+}
+
+// Truncate cuts text down to what fits on one line of the display.
+func Truncate(d IOledDevice, text string) string {
+	width, _ := d.Size()
+	n := len(text)
+	for n > 0 && d.TextWidth(text[:n]) > width {
+		n--
+	}
+	return text[:n]
+}
+
+// Ellipsis is Truncate, but ends text that was cut short with "..." so it's
+// clear there is more, e.g. "Pixels 4 Loop..." for a long app name.
+func Ellipsis(d IOledDevice, text string) string {
+	if Fits(d, text) {
+		return text
+	}
+	width, _ := d.Size()
+	n := len(text)
+	for n > 0 && d.TextWidth(text[:n]+EllipsisText) > width {
+		n--
+	}
+	return strings.TrimRight(text[:n], " ") + EllipsisText
+}
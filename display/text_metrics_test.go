@@ -0,0 +1,45 @@
+// Text metrics tests
+package display
+
+import "testing"
+
+func TestTextMetrics(t *testing.T) {
+	oled := NewMockOledDevice(3, 16)
+	if w, h := oled.Size(); w != 128 || h != 32 {
+		t.Errorf("Expected 128x32, got %dx%d", w, h)
+	}
+	if oled.CharsPerLine() != 16 || oled.TextWidth("abc") != 24 {
+		t.Errorf("Expected 8 pixel characters, got %d per line and %d for 3", oled.CharsPerLine(), oled.TextWidth("abc"))
+	}
+	tiny := NewMockOledDevice(3, 21)
+	if tiny.TextWidth("abc") != 18 {
+		t.Errorf("Expected TinyFont characters to be 6 pixels, got %d for 3", tiny.TextWidth("abc"))
+	}
+	buffered := NewBufferedDisplay(tiny, 3)
+	if buffered.CharsPerLine() != 21 || buffered.TextWidth("abc") != 18 {
+		t.Error("Expected BufferedDisplay to pass the backend's metrics through")
+	}
+
+	// The 8x8 adapter measures its own font
+	adapter := NewAdapter8x8(NewFramebuffer(128, 64), 4)
+	if w, h := adapter.Size(); w != 128 || h != 64 || adapter.CharsPerLine() != 16 || adapter.TextWidth("ab") != 16 {
+		t.Errorf("Unexpected adapter metrics %dx%d, %d per line", w, h, adapter.CharsPerLine())
+	}
+
+	long := "Pixels 4 Loop (v2)"
+	if Fits(oled, long) || !Fits(tiny, long) {
+		t.Error("Expected 18 chars to only fit with TinyFont")
+	}
+	if got := Truncate(oled, long); got != "Pixels 4 Loop (v" {
+		t.Errorf("Unexpected truncation %q", got)
+	}
+	if got := Ellipsis(oled, long); got != "Pixels 4 Loop..." {
+		t.Errorf("Unexpected ellipsis %q", got)
+	}
+	if got := Ellipsis(oled, "Short"); got != "Short" {
+		t.Errorf("Expected text that fits to be left alone, got %q", got)
+	}
+	if got := Ellipsis(tiny, long); got != long {
+		t.Errorf("Expected TinyFont to fit the whole name, got %q", got)
+	}
+}
@@ -40,7 +40,7 @@ func RunApp(idx int, hw *controls.Controls) {
 var doubleButtonPressLastMs int64 = 0
 
 func ShouldExit(hw *controls.Controls) bool {
-	now := time.Now().UnixMilli()
+	now := hw.Clock.Now().UnixMilli()
 	if hw.B1.Pressed() && hw.B2.Pressed() {
 		if doubleButtonPressLastMs == 0 {
 			doubleButtonPressLastMs = now
@@ -59,6 +59,6 @@ func SplashScreen(hw *controls.Controls) {
 	hw.Display.WriteLine(0, "EuroPi Simplified")
 	hw.Display.WriteLine(1, "by TinyGo "+version)
 	hw.Display.Display()
-	time.Sleep(1 * time.Second)
+	hw.Clock.Sleep(1 * time.Second)
 	hw.Display.ClearDisplay()
 }
@@ -1,8 +1,33 @@
 // App logic tests
 package firmware
 
-import "testing"
+import (
+	"europi/clock"
+	"europi/controls"
+	"europi/display"
+	"testing"
+	"time"
+)
 
 func TestBoot(t *testing.T) {
 	// TODO: Add app logic tests
 }
+
+func TestShouldExitAfterTwoSeconds(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	hw := controls.SetupMockEuroPiWithClock(display.NewMockOledDevice(3, 16), clk)
+	hw.B1.(*controls.MockButton).SetPressed(true)
+	hw.B2.(*controls.MockButton).SetPressed(true)
+
+	if ShouldExit(hw) {
+		t.Fatal("ShouldExit fired as soon as both buttons were pressed")
+	}
+	clk.Advance(1999 * time.Millisecond)
+	if ShouldExit(hw) {
+		t.Fatal("ShouldExit fired before 2s had elapsed")
+	}
+	clk.Advance(1 * time.Millisecond)
+	if !ShouldExit(hw) {
+		t.Fatal("ShouldExit did not fire after holding both buttons for 2s")
+	}
+}
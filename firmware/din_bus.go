@@ -0,0 +1,174 @@
+package firmware
+
+import (
+	"europi/clock"
+	"europi/controls"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DINEvent is an edge on the digital input, timestamped in the interrupt handler
+// so measurements don't include main loop latency.
+type DINEvent struct {
+	Rising bool
+	At     time.Time
+}
+
+// DINBus fans the DIN edges out to any number of subscribers. It owns the DIN
+// edge handlers while anyone is subscribed, so apps using the bus must not call
+// hw.DIN.SetEdgeHandlers themselves.
+type DINBus struct {
+	din   controls.IDigitalInput
+	clock clock.Clock
+	hw    *controls.Controls // set on shared buses (DINBusFor), which go with their last subscriber
+
+	mu   sync.Mutex                         // serialises Subscribe and Close
+	subs atomic.Pointer[[]*DINSubscription] // copy on write, read lock free by the ISR
+}
+
+// DINSubscription receives DIN events on a buffered channel. Events that arrive
+// while the channel is full are dropped and counted.
+type DINSubscription struct {
+	bus     *DINBus
+	events  chan DINEvent
+	dropped atomic.Uint32
+}
+
+func NewDINBus(din controls.IDigitalInput, clk clock.Clock) *DINBus {
+	return &DINBus{din: din, clock: clk}
+}
+
+// dinBuses holds the shared bus of each Controls while it has subscribers.
+// The entry is removed when the last subscriber closes, so a Controls from a
+// test or the mock isn't kept alive by its bus.
+var (
+	dinBusesMu sync.Mutex
+	dinBuses   = map[*controls.Controls]*DINBus{}
+)
+
+// DINBusFor returns the shared DIN bus for hw. Once its last subscriber
+// closes, the next call returns a new bus.
+func DINBusFor(hw *controls.Controls) *DINBus {
+	dinBusesMu.Lock()
+	defer dinBusesMu.Unlock()
+	return dinBusForLocked(hw)
+}
+
+func dinBusForLocked(hw *controls.Controls) *DINBus {
+	bus, ok := dinBuses[hw]
+	if !ok {
+		bus = NewDINBus(hw.DIN, hw.Clock)
+		bus.hw = hw
+		dinBuses[hw] = bus
+	}
+	return bus
+}
+
+// SubscribeDIN subscribes to the shared DIN bus of hw with a channel of the given size.
+func SubscribeDIN(hw *controls.Controls, size int) *DINSubscription {
+	// Held while subscribing, so the bus can't be released in between
+	dinBusesMu.Lock()
+	defer dinBusesMu.Unlock()
+	return dinBusForLocked(hw).Subscribe(size)
+}
+
+// release drops a shared bus from dinBuses if nobody has subscribed since its
+// last subscriber closed.
+func (b *DINBus) release() {
+	dinBusesMu.Lock()
+	defer dinBusesMu.Unlock()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if dinBuses[b.hw] == b && b.Subscribers() == 0 {
+		delete(dinBuses, b.hw)
+	}
+}
+
+// Subscribe adds a subscriber with a channel of the given size. The DIN edge
+// handlers are installed with the first subscriber.
+func (b *DINBus) Subscribe(size int) *DINSubscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sub := &DINSubscription{bus: b, events: make(chan DINEvent, size)}
+	var subs []*DINSubscription
+	if old := b.subs.Load(); old != nil {
+		subs = append(subs, *old...)
+	}
+	subs = append(subs, sub)
+	b.subs.Store(&subs)
+	if len(subs) == 1 {
+		b.din.SetEdgeHandlers(
+			func() { b.publish(true) },
+			func() { b.publish(false) },
+		)
+	}
+	return sub
+}
+
+// publish runs in the interrupt handler. The select/default pattern guarantees
+// the ISR never blocks on a slow subscriber.
+func (b *DINBus) publish(rising bool) {
+	subs := b.subs.Load()
+	if subs == nil {
+		return
+	}
+	ev := DINEvent{Rising: rising, At: b.clock.Now()}
+	for _, sub := range *subs {
+		select {
+		case sub.events <- ev:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribers returns the number of current subscribers.
+func (b *DINBus) Subscribers() int {
+	if subs := b.subs.Load(); subs != nil {
+		return len(*subs)
+	}
+	return 0
+}
+
+func (s *DINSubscription) Events() <-chan DINEvent {
+	return s.events
+}
+
+// Dropped returns the number of events dropped because the channel was full.
+func (s *DINSubscription) Dropped() int {
+	return int(s.dropped.Load())
+}
+
+// Close unsubscribes. The DIN interrupt is removed with the last subscriber,
+// and a shared bus is released.
+func (s *DINSubscription) Close() {
+	if s.bus.unsubscribe(s) && s.bus.hw != nil {
+		s.bus.release()
+	}
+}
+
+// unsubscribe removes s, returning true if it was the last subscriber.
+func (b *DINBus) unsubscribe(s *DINSubscription) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	old := b.subs.Load()
+	if old == nil {
+		return false
+	}
+	var subs []*DINSubscription
+	for _, other := range *old {
+		if other != s {
+			subs = append(subs, other)
+		}
+	}
+	if len(subs) == len(*old) {
+		return false // already closed
+	}
+	b.subs.Store(&subs)
+	if len(subs) == 0 {
+		b.din.UnsetInterrupt()
+		return true
+	}
+	return false
+}
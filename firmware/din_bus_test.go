@@ -0,0 +1,99 @@
+// DIN event bus tests, driven by the mock digital input and a virtual clock
+package firmware
+
+import (
+	"europi/clock"
+	"europi/controls"
+	"europi/display"
+	"testing"
+	"time"
+)
+
+func TestDINBusTimestampsAndFanOut(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	hw := controls.SetupMockEuroPiWithClock(display.NewMockOledDevice(3, 16), clk)
+	din := hw.DIN.(*controls.MockDigitalInput)
+	a := SubscribeDIN(hw, 4)
+	b := SubscribeDIN(hw, 4)
+	defer a.Close()
+	defer b.Close()
+
+	start := clk.Now()
+	din.SetState(true)
+	clk.Advance(10 * time.Millisecond)
+	din.SetState(false)
+	clk.Advance(time.Second) // the main loop being slow must not affect the timestamps
+
+	want := []DINEvent{{Rising: true, At: start}, {Rising: false, At: start.Add(10 * time.Millisecond)}}
+	for _, sub := range []*DINSubscription{a, b} {
+		for _, w := range want {
+			select {
+			case ev := <-sub.Events():
+				if ev != w {
+					t.Errorf("Expected %+v, got %+v", w, ev)
+				}
+			default:
+				t.Fatalf("Expected %+v, got nothing", w)
+			}
+		}
+	}
+}
+
+func TestDINBusDropsAndClose(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	din := &controls.MockDigitalInput{}
+	bus := NewDINBus(din, clk)
+	slow := bus.Subscribe(1)
+	fast := bus.Subscribe(8)
+
+	for i := 0; i < 3; i++ {
+		din.SetState(true)
+		din.SetState(false)
+	}
+	if slow.Dropped() != 5 {
+		t.Errorf("Expected the slow subscriber to drop 5 events, got %d", slow.Dropped())
+	}
+	if fast.Dropped() != 0 || len(fast.Events()) != 6 {
+		t.Errorf("Expected the fast subscriber to get all 6 events, got %d (dropped %d)", len(fast.Events()), fast.Dropped())
+	}
+
+	slow.Close()
+	slow.Close() // closing twice is harmless
+	if bus.Subscribers() != 1 {
+		t.Fatalf("Expected 1 subscriber after Close, got %d", bus.Subscribers())
+	}
+	fast.Close()
+	din.SetState(true)
+	if len(slow.Events()) != 1 || len(fast.Events()) != 6 {
+		t.Error("Expected no events after the last subscriber closed")
+	}
+}
+
+func TestSharedDINBusReleasedWithLastSubscriber(t *testing.T) {
+	hw := controls.SetupMockEuroPiWithClock(display.NewMockOledDevice(3, 16), clock.NewVirtual(time.Time{}))
+	a := SubscribeDIN(hw, 4)
+	b := SubscribeDIN(hw, 4)
+	bus := DINBusFor(hw)
+	a.Close()
+	if DINBusFor(hw) != bus {
+		t.Fatal("Expected the bus to be kept while it has a subscriber")
+	}
+	b.Close()
+	dinBusesMu.Lock()
+	_, kept := dinBuses[hw]
+	dinBusesMu.Unlock()
+	if kept {
+		t.Fatal("Expected the bus to be released with its last subscriber")
+	}
+
+	// Subscribing again gets a new bus, which receives the edges
+	c := SubscribeDIN(hw, 4)
+	defer c.Close()
+	if DINBusFor(hw) == bus {
+		t.Error("Expected a new bus after the old one was released")
+	}
+	hw.DIN.(*controls.MockDigitalInput).SetState(true)
+	if len(c.Events()) != 1 {
+		t.Errorf("Expected the new subscriber to get the edge, got %d events", len(c.Events()))
+	}
+}
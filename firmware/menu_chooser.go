@@ -1,7 +1,9 @@
 package firmware
 
 import (
+	"europi/buttons"
 	"europi/controls"
+	"europi/display"
 	"time"
 )
 
@@ -28,11 +30,18 @@ func ScrollingMenu(items []string, hw *controls.Controls, visibleLines int) int
 	// Insert menu header at the top
 	menuItems := make([]string, numItems+1)
 	menuItems[0] = "--- MENU ---"
-	copy(menuItems[1:], items)
+	// Long names end in "..." rather than being cut off mid word
+	for i, item := range items {
+		menuItems[i+1] = display.Ellipsis(hw.Display, item)
+	}
 	totalItems := numItems + 1
 	selected := 1 // Start at first selectable item
 	selectedLast := -1
 	lastK2 := -1
+	// B2 presses are caught by the button interrupt, so a quick tap is never
+	// missed while the display is being redrawn
+	btnMgr := buttons.NewWithClock(hw.B1, hw.B2, hw.Clock)
+	defer btnMgr.Close()
 	for {
 		k2 := hw.K2.Value()
 		updateDisplay := false
@@ -89,16 +98,14 @@ func ScrollingMenu(items []string, hw *controls.Controls, visibleLines int) int
 			hw.Display.Display()
 		}
 
-		if hw.B2.Pressed() && !hw.B1.Pressed() {
-			for hw.B2.Pressed() {
-				time.Sleep(10 * time.Millisecond)
-			}
+		switch btnMgr.Update() {
+		case buttons.B2Press:
 			// Return selected-1 so 0 is first app, etc. Never return 0 (header)
 			return selected - 1
 		}
 		if ShouldExit(hw) {
 			return -1
 		}
-		time.Sleep(2 * time.Millisecond)
+		hw.Clock.Sleep(2 * time.Millisecond)
 	}
 }
@@ -0,0 +1,78 @@
+package firmware
+
+import (
+	"europi/buttons"
+	"europi/clock"
+	"europi/controls"
+	"europi/display"
+	"europi/display/displaytest"
+	"testing"
+	"time"
+)
+
+// runMenu shows a ScrollingMenu with K2 at k2, presses B2 and returns the
+// selection. The menu runs on a virtual clock that only moves while it sleeps,
+// so the press is held for longer than the debounce however slow the host is.
+func runMenu(t *testing.T, oled *display.MockOledDevice, items []string, k2 int) int {
+	t.Helper()
+	clk := clock.NewVirtual(time.Time{})
+	hw := controls.SetupMockEuroPiWithClock(oled, clk)
+	hw.K2.(*controls.MockKnob).SetValue(k2)
+	done := make(chan int, 1)
+	go func() { done <- ScrollingMenu(items, hw, oled.NumLines()) }()
+
+	// advance moves the clock on by d, a step at a time once the menu is asleep
+	advance := func(d time.Duration) (int, bool) {
+		deadline := time.Now().Add(2 * time.Second)
+		for elapsed := time.Duration(0); elapsed < d; {
+			select {
+			case idx := <-done:
+				return idx, true
+			default:
+			}
+			if clk.Pending() == 0 {
+				if time.Now().After(deadline) {
+					t.Fatal("ScrollingMenu neither slept nor returned")
+				}
+				time.Sleep(100 * time.Microsecond)
+				continue
+			}
+			clk.Advance(time.Millisecond)
+			elapsed += time.Millisecond
+		}
+		return -1, false
+	}
+
+	advance(20 * time.Millisecond)
+	b2 := hw.B2.(*controls.MockButton)
+	b2.SetPressed(true)
+	advance(2 * buttons.DefaultThresholds().Debounce)
+	b2.SetPressed(false)
+	if idx, ok := advance(100 * time.Millisecond); ok {
+		return idx
+	}
+	t.Fatal("ScrollingMenu didn't return after B2 was pressed")
+	return -1
+}
+
+func TestScrollingMenuGolden(t *testing.T) {
+	items := []string{"Diagnostic", "Hello World", "Font Display", "Menu Fun", "Pulse Sync"}
+	for _, tc := range []struct {
+		name     string
+		numLines int
+		k2       int
+		want     int
+	}{
+		{"menu-3-lines-first", 3, 0, 0},
+		{"menu-3-lines-scrolled", 3, 60, 2},
+		{"menu-4-lines-last", 4, 100, 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			oled := display.NewMockOledDevice(tc.numLines, 16)
+			if got := runMenu(t, oled, items, tc.k2); got != tc.want {
+				t.Errorf("Expected item %d to be selected, got %d", tc.want, got)
+			}
+			displaytest.AssertGolden(t, oled.Framebuffer(), tc.name)
+		})
+	}
+}
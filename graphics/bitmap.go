@@ -0,0 +1,59 @@
+package graphics
+
+// Bitmap is a monochrome image, e.g. an icon or sprite. Data is row by row,
+// each row starting on a new byte with the leftmost pixel in the top bit.
+type Bitmap struct {
+	Width  int16
+	Height int16
+	Data   []byte
+}
+
+// NewBitmap builds a bitmap from rows of text, '#' for a lit pixel and
+// anything else for a transparent one, which is handy for small sprites:
+//
+//	arrow := graphics.NewBitmap(
+//		"..#..",
+//		".###.",
+//		"#####",
+//	)
+func NewBitmap(rows ...string) Bitmap {
+	b := Bitmap{Height: int16(len(rows))}
+	for _, row := range rows {
+		b.Width = max(b.Width, int16(len(row)))
+	}
+	stride := (int(b.Width) + 7) / 8
+	b.Data = make([]byte, stride*len(rows))
+	for y, row := range rows {
+		for x := 0; x < len(row); x++ {
+			if row[x] == '#' {
+				b.Data[y*stride+x/8] |= 0x80 >> (x % 8)
+			}
+		}
+	}
+	return b
+}
+
+// At reports whether the pixel at x, y of the bitmap is lit.
+func (b Bitmap) At(x, y int16) bool {
+	if x < 0 || y < 0 || x >= b.Width || y >= b.Height {
+		return false
+	}
+	stride := (int(b.Width) + 7) / 8
+	i := int(y)*stride + int(x)/8
+	if i >= len(b.Data) {
+		return false
+	}
+	return b.Data[i]&(0x80>>(x%8)) != 0
+}
+
+// Bitmap draws the lit pixels of b with its top left corner at x, y. Unlit
+// pixels are transparent, so sprites can be drawn over a background.
+func (c *Canvas) Bitmap(x, y int16, b Bitmap) {
+	for j := int16(0); j < b.Height; j++ {
+		for i := int16(0); i < b.Width; i++ {
+			if b.At(i, j) {
+				c.Pixel(x+i, y+j)
+			}
+		}
+	}
+}
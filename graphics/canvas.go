@@ -0,0 +1,174 @@
+// Package graphics draws lines, shapes, bitmaps and text onto the OLED (or
+// the host Framebuffer). Everything is clipped to the screen, so shapes may
+// hang off the edges without the driver rejecting them.
+package graphics
+
+import (
+	"europi/display"
+)
+
+// Mode is how a shape's pixels are combined with what's already on screen.
+type Mode int
+
+const (
+	// Set turns pixels on.
+	Set Mode = iota
+	// Clear turns pixels off, e.g. to erase a shape.
+	Clear
+	// XOR flips pixels, so drawing the same shape twice restores the screen.
+	// It needs a device that can read pixels back (see PixelReader), otherwise it acts like Set.
+	XOR
+)
+
+// PixelReader is implemented by devices that can report a pixel's state,
+// which the ssd1306 driver and display.Framebuffer both do.
+type PixelReader interface {
+	GetPixel(x, y int16) bool
+}
+
+// Canvas draws onto a display device in the given Mode.
+type Canvas struct {
+	dev    display.ISSD1306Device
+	reader PixelReader
+	width  int16
+	height int16
+	Mode   Mode
+}
+
+// New returns a Canvas drawing onto dev in Set mode.
+func New(dev display.ISSD1306Device) *Canvas {
+	width, height := dev.Size()
+	reader, _ := dev.(PixelReader)
+	return &Canvas{dev: dev, reader: reader, width: width, height: height}
+}
+
+// WithMode returns a copy of the canvas that draws in mode, e.g.
+// c.WithMode(graphics.XOR).FillRect(...) to invert an area.
+func (c *Canvas) WithMode(mode Mode) *Canvas {
+	copy := *c
+	copy.Mode = mode
+	return &copy
+}
+
+// Size returns the width and height of the screen.
+func (c *Canvas) Size() (width, height int16) {
+	return c.width, c.height
+}
+
+// Device returns the underlying display device, e.g. to call Display.
+func (c *Canvas) Device() display.ISSD1306Device {
+	return c.dev
+}
+
+func (c *Canvas) inBounds(x, y int16) bool {
+	return x >= 0 && y >= 0 && x < c.width && y < c.height
+}
+
+// Pixel draws a single pixel, ignoring it if it's off screen.
+func (c *Canvas) Pixel(x, y int16) {
+	if !c.inBounds(x, y) {
+		return
+	}
+	switch c.Mode {
+	case Clear:
+		c.dev.SetPixel(x, y, display.ColorBlack)
+	case XOR:
+		if c.reader != nil && c.reader.GetPixel(x, y) {
+			c.dev.SetPixel(x, y, display.ColorBlack)
+		} else {
+			c.dev.SetPixel(x, y, display.ColorWhite)
+		}
+	default:
+		c.dev.SetPixel(x, y, display.ColorWhite)
+	}
+}
+
+// HLine draws a horizontal line w pixels long starting at x, y.
+func (c *Canvas) HLine(x, y, w int16) {
+	c.FillRect(x, y, w, 1)
+}
+
+// VLine draws a vertical line h pixels long starting at x, y.
+func (c *Canvas) VLine(x, y, h int16) {
+	c.FillRect(x, y, 1, h)
+}
+
+// Line draws a line between two points, inclusive, with Bresenham's algorithm.
+func (c *Canvas) Line(x0, y0, x1, y1 int16) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := int16(1), int16(1)
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		c.Pixel(x0, y0)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// Rect draws the outline of a rectangle.
+func (c *Canvas) Rect(x, y, w, h int16) {
+	if w <= 0 || h <= 0 {
+		return
+	}
+	c.HLine(x, y, w)
+	if h > 1 {
+		c.HLine(x, y+h-1, w)
+	}
+	if h > 2 {
+		c.VLine(x, y+1, h-2)
+		if w > 1 {
+			c.VLine(x+w-1, y+1, h-2)
+		}
+	}
+}
+
+// FillRect fills a rectangle, clipped to the screen.
+func (c *Canvas) FillRect(x, y, w, h int16) {
+	r := Rect{x, y, w, h}.Clip(c.width, c.height)
+	if r.Empty() {
+		return
+	}
+	if c.Mode == XOR {
+		for j := r.Y; j < r.Y+r.H; j++ {
+			for i := r.X; i < r.X+r.W; i++ {
+				c.Pixel(i, j)
+			}
+		}
+		return
+	}
+	colour := display.ColorWhite
+	if c.Mode == Clear {
+		colour = display.ColorBlack
+	}
+	// Clipped, so the driver won't reject it
+	c.dev.FillRectangle(r.X, r.Y, r.W, r.H, colour)
+}
+
+// InvertRect flips every pixel in a rectangle, e.g. to highlight part of a line.
+func (c *Canvas) InvertRect(x, y, w, h int16) {
+	c.WithMode(XOR).FillRect(x, y, w, h)
+}
+
+func abs(v int16) int16 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,118 @@
+package graphics
+
+import "math"
+
+type point struct{ x, y int16 }
+
+// circlePoints returns the outline of a circle with the midpoint algorithm,
+// each pixel once so XOR mode doesn't flip any pixel back.
+func circlePoints(cx, cy, r int16) []point {
+	if r < 0 {
+		return nil
+	}
+	if r == 0 {
+		return []point{{cx, cy}}
+	}
+	seen := make(map[point]bool)
+	var points []point
+	add := func(x, y int16) {
+		p := point{cx + x, cy + y}
+		if !seen[p] {
+			seen[p] = true
+			points = append(points, p)
+		}
+	}
+	x, y := r, int16(0)
+	err := 1 - r
+	for x >= y {
+		add(x, y)
+		add(y, x)
+		add(-y, x)
+		add(-x, y)
+		add(-x, -y)
+		add(-y, -x)
+		add(y, -x)
+		add(x, -y)
+		y++
+		if err < 0 {
+			err += 2*y + 1
+		} else {
+			x--
+			err += 2*(y-x) + 1
+		}
+	}
+	return points
+}
+
+// Circle draws the outline of a circle of radius r centred on cx, cy.
+func (c *Canvas) Circle(cx, cy, r int16) {
+	for _, p := range circlePoints(cx, cy, r) {
+		c.Pixel(p.x, p.y)
+	}
+}
+
+// FillCircle draws a solid circle of radius r centred on cx, cy, covering
+// exactly the pixels of Circle and everything inside it.
+func (c *Canvas) FillCircle(cx, cy, r int16) {
+	if r < 0 {
+		return
+	}
+	// Each row spans between the outline's leftmost and rightmost pixels
+	left := make([]int16, 2*r+1)
+	right := make([]int16, 2*r+1)
+	for i := range left {
+		left[i], right[i] = cx, cx
+	}
+	for _, p := range circlePoints(cx, cy, r) {
+		row := p.y - cy + r
+		left[row] = min(left[row], p.x)
+		right[row] = max(right[row], p.x)
+	}
+	for row := range left {
+		c.HLine(left[row], cy-r+int16(row), right[row]-left[row]+1)
+	}
+}
+
+// Arc draws part of a circle's outline from start to end degrees. Angles are
+// measured clockwise from 12 o'clock, like a knob, so Arc(cx, cy, r, -135, 135)
+// is the sweep of a knob from 7 to 5 o'clock. end must not be less than start.
+func (c *Canvas) Arc(cx, cy, r int16, start, end float64) {
+	for _, p := range circlePoints(cx, cy, r) {
+		if inArc(angleOf(p.x-cx, p.y-cy), start, end) {
+			c.Pixel(p.x, p.y)
+		}
+	}
+}
+
+// PointOnCircle returns the pixel at angle degrees (clockwise from 12
+// o'clock) on a circle, e.g. the end of a knob pointer.
+func PointOnCircle(cx, cy, r int16, angle float64) (x, y int16) {
+	rad := angle * math.Pi / 180
+	x = cx + int16(math.Round(float64(r)*math.Sin(rad)))
+	y = cy - int16(math.Round(float64(r)*math.Cos(rad)))
+	return x, y
+}
+
+// angleOf returns the angle of an offset from the centre, clockwise from 12 o'clock, 0 to 360.
+func angleOf(dx, dy int16) float64 {
+	a := math.Atan2(float64(dx), float64(-dy)) * 180 / math.Pi
+	if a < 0 {
+		a += 360
+	}
+	return a
+}
+
+func inArc(a, start, end float64) bool {
+	sweep := end - start
+	if sweep >= 360 {
+		return true
+	}
+	if sweep < 0 {
+		return false
+	}
+	rel := math.Mod(a-start, 360)
+	if rel < 0 {
+		rel += 360
+	}
+	return rel <= sweep
+}
@@ -0,0 +1,196 @@
+package graphics
+
+import (
+	"europi/display"
+	"strings"
+	"testing"
+)
+
+func newTestCanvas(w, h int16) (*Canvas, *display.Framebuffer) {
+	fb := display.NewFramebuffer(w, h)
+	return New(fb), fb
+}
+
+func expectPixels(t *testing.T, fb *display.Framebuffer, rows ...string) {
+	t.Helper()
+	want := strings.Join(rows, "\n") + "\n"
+	if got := fb.String(); got != want {
+		t.Errorf("Expected:\n%s\nGot:\n%s", want, got)
+	}
+}
+
+func TestLinesAndRects(t *testing.T) {
+	c, fb := newTestCanvas(8, 5)
+	c.Line(0, 0, 7, 4)
+	expectPixels(t, fb,
+		"#.......",
+		".##.....",
+		"...##...",
+		".....##.",
+		".......#",
+	)
+
+	fb.ClearBuffer()
+	c.Rect(1, 1, 4, 3)
+	c.FillRect(6, -2, 5, 4) // clipped rather than rejected like the driver does
+	expectPixels(t, fb,
+		"......##",
+		".####.##",
+		".#..#...",
+		".####...",
+		"........",
+	)
+
+	c.WithMode(Clear).HLine(0, 1, 8)
+	c.InvertRect(0, 3, 3, 2)
+	expectPixels(t, fb,
+		"......##",
+		"........",
+		".#..#...",
+		"#..##...",
+		"###.....",
+	)
+	if c.Mode != Set {
+		t.Error("Expected WithMode to leave the original canvas alone")
+	}
+}
+
+func TestXORTwiceRestores(t *testing.T) {
+	c, fb := newTestCanvas(16, 16)
+	c.FillRect(2, 2, 6, 6)
+	before := fb.String()
+	x := c.WithMode(XOR)
+	x.Circle(8, 8, 6)
+	x.Line(0, 15, 15, 0)
+	x.Text(1, 4, "Hi")
+	if fb.String() == before {
+		t.Fatal("Expected XOR drawing to change the screen")
+	}
+	x.Text(1, 4, "Hi")
+	x.Line(0, 15, 15, 0)
+	x.Circle(8, 8, 6)
+	if got := fb.String(); got != before {
+		t.Errorf("Expected drawing twice in XOR mode to restore the screen, got:\n%s", got)
+	}
+}
+
+func TestCirclesAndArcs(t *testing.T) {
+	c, fb := newTestCanvas(7, 7)
+	c.Circle(3, 3, 3)
+	outline := fb.String()
+	expectPixels(t, fb,
+		"..###..",
+		".#...#.",
+		"#.....#",
+		"#.....#",
+		"#.....#",
+		".#...#.",
+		"..###..",
+	)
+
+	fb.ClearBuffer()
+	c.FillCircle(3, 3, 3)
+	filled := fb.String()
+	for i := range outline {
+		if outline[i] == '#' && filled[i] != '#' {
+			t.Fatalf("Expected FillCircle to cover the outline, got:\n%s", filled)
+		}
+	}
+	if !fb.GetPixel(3, 3) || !fb.GetPixel(1, 3) {
+		t.Error("Expected the inside to be filled")
+	}
+
+	// Right half only: clockwise from 12 to 6 o'clock
+	fb.ClearBuffer()
+	c.Arc(3, 3, 3, 0, 180)
+	expectPixels(t, fb,
+		"...##..",
+		".....#.",
+		"......#",
+		"......#",
+		"......#",
+		".....#.",
+		"...##..",
+	)
+	if x, y := PointOnCircle(3, 3, 3, 90); x != 6 || y != 3 {
+		t.Errorf("Expected 90 degrees to be 3 o'clock, got %d,%d", x, y)
+	}
+}
+
+func TestBitmapAndText(t *testing.T) {
+	c, fb := newTestCanvas(8, 4)
+	arrow := NewBitmap(
+		"..#..",
+		".###.",
+		"#####",
+	)
+	if arrow.Width != 5 || arrow.Height != 3 || !arrow.At(2, 0) || arrow.At(0, 0) {
+		t.Fatalf("Unexpected bitmap %+v", arrow)
+	}
+	c.Pixel(0, 0)
+	c.Bitmap(4, 1, arrow) // unlit pixels are transparent, and it hangs off the right edge
+	expectPixels(t, fb,
+		"#.......",
+		"......#.",
+		".....###",
+		"....####",
+	)
+
+	// Text draws the same glyphs as the display's 8x8 renderer, but keeps
+	// going past the edge and partly draws characters that are off screen
+	c, fb = newTestCanvas(20, 8)
+	want := display.NewFramebuffer(20, 8)
+	display.DrawFont8x8Text(want, 0, 0, "AB", display.ColorWhite)
+	if end := c.Text(0, 0, "ABC"); end != 24 {
+		t.Errorf("Expected text to end at 24, got %d", end)
+	}
+	for x := int16(0); x < 16; x++ {
+		for y := int16(0); y < 8; y++ {
+			if fb.GetPixel(x, y) != want.GetPixel(x, y) {
+				t.Fatalf("Text differs from the font at %d,%d:\n%s", x, y, fb.String())
+			}
+		}
+	}
+	lit := 0
+	for x := int16(16); x < 20; x++ {
+		for y := int16(0); y < 8; y++ {
+			if fb.GetPixel(x, y) {
+				lit++
+			}
+		}
+	}
+	if lit == 0 {
+		t.Error("Expected the clipped C to be partly drawn")
+	}
+
+	// Inverse text is the exact complement within its cells
+	fb.ClearBuffer()
+	c.TextInverse(0, 0, "AB")
+	for x := int16(0); x < 16; x++ {
+		for y := int16(0); y < 8; y++ {
+			if fb.GetPixel(x, y) == want.GetPixel(x, y) {
+				t.Fatalf("Expected inverse text at %d,%d:\n%s", x, y, fb.String())
+			}
+		}
+	}
+	if TextWidth("abc") != 24 {
+		t.Error("Expected 8 pixels per character")
+	}
+}
+
+func TestRectHelpers(t *testing.T) {
+	r := Rect{-2, 30, 10, 5}.Clip(128, 32)
+	if r != (Rect{0, 30, 8, 2}) {
+		t.Errorf("Unexpected clip %+v", r)
+	}
+	if !(Rect{120, 0, 10, 10}.Clip(128, 32) == Rect{120, 0, 8, 10}) {
+		t.Error("Expected the right edge to be clipped")
+	}
+	if !(Rect{200, 0, 10, 10}).Clip(128, 32).Empty() {
+		t.Error("Expected an off screen rect to be empty")
+	}
+	a, b := Rect{0, 0, 4, 4}, Rect{5, 0, 4, 4}
+	if a.Overlaps(b, 0) || !a.Overlaps(b, 2) {
+		t.Error("Expected spacing to count as overlap")
+	}
+}
@@ -0,0 +1,40 @@
+package graphics
+
+// Rect is a rectangle on screen, X and Y being its top left corner.
+type Rect struct{ X, Y, W, H int16 }
+
+// Empty reports whether the rectangle has no pixels.
+func (r Rect) Empty() bool {
+	return r.W <= 0 || r.H <= 0
+}
+
+// Clip returns the part of the rectangle that is on a width x height screen.
+func (r Rect) Clip(width, height int16) Rect {
+	if r.X < 0 {
+		r.W += r.X
+		r.X = 0
+	}
+	if r.Y < 0 {
+		r.H += r.Y
+		r.Y = 0
+	}
+	if r.X+r.W > width {
+		r.W = width - r.X
+	}
+	if r.Y+r.H > height {
+		r.H = height - r.Y
+	}
+	if r.W < 0 {
+		r.W = 0
+	}
+	if r.H < 0 {
+		r.H = 0
+	}
+	return r
+}
+
+// Overlaps reports whether two rectangles overlap or come within spacing pixels of each other.
+func (r Rect) Overlaps(o Rect, spacing int16) bool {
+	return r.X < o.X+o.W+spacing && r.X+r.W+spacing > o.X &&
+		r.Y < o.Y+o.H+spacing && r.Y+r.H+spacing > o.Y
+}
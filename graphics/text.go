@@ -0,0 +1,61 @@
+package graphics
+
+import "europi/display"
+
+// CharWidth and CharHeight are the size of a character in the 8x8 font.
+const (
+	CharWidth  = 8
+	CharHeight = 8
+)
+
+// Text draws text in the 8x8 font with its top left corner at x, y and
+// returns the x just past the last character. Unlike display.DrawFont8x8Text,
+// characters hanging off the screen are drawn in part, so text can scroll.
+func (c *Canvas) Text(x, y int16, text string) int16 {
+	for i := 0; i < len(text); i++ {
+		if x >= c.width {
+			break
+		}
+		c.char(x, y, text[i], true)
+		x += CharWidth
+	}
+	return x
+}
+
+// TextInverse draws text dark on a lit 8x8 cell per character, like the
+// highlighted menu line. In XOR mode the cells are flipped instead.
+func (c *Canvas) TextInverse(x, y int16, text string) int16 {
+	for i := 0; i < len(text); i++ {
+		if x >= c.width {
+			break
+		}
+		c.char(x, y, text[i], false)
+		x += CharWidth
+	}
+	return x
+}
+
+// TextWidth returns the width of text in pixels in the 8x8 font.
+func TextWidth(text string) int16 {
+	return int16(len(text) * CharWidth)
+}
+
+// char draws a character's glyph pixels if lit, otherwise the pixels around them.
+func (c *Canvas) char(x, y int16, ch byte, lit bool) {
+	data := display.GetFont8x8CharacterData(ch)
+	if x+CharWidth <= 0 || y+CharHeight <= 0 {
+		return
+	}
+	// Columns with the LSB at the top, as in display.DrawFont8x8Character
+	for col := int16(0); col < CharWidth; col++ {
+		var bits uint8
+		if data != nil {
+			bits = data[col]
+		}
+		for row := int16(0); row < CharHeight; row++ {
+			if (bits&(1<<row) != 0) == lit {
+				c.Pixel(x+col, y+row)
+			}
+		}
+	}
+}
@@ -28,11 +28,19 @@ func SetButtonPressed(btn controls.IButton, pressed bool) {
 }
 
 func SetDigitalInputValue(din controls.IDigitalInput, value bool) {
-	if mock, ok := din.(interface{ SetValue(bool) }); ok {
-		mock.SetValue(value)
+	if mock, ok := din.(interface{ SetState(bool) }); ok {
+		mock.SetState(value)
 	}
 }
 
+// PlayPulseTrain plays a clock signal into a mock digital input. Returns a func to stop it.
+func PlayPulseTrain(din controls.IDigitalInput, train controls.PulseTrain) (stop func()) {
+	if mock, ok := din.(*controls.MockDigitalInput); ok {
+		return mock.PlayPulseTrain(train)
+	}
+	return func() {}
+}
+
 func SetAnalogueInputValue(ain controls.IAnalogueInput, volts float64) {
 	if mock, ok := ain.(interface{ SetVolts(float64) }); ok {
 		mock.SetVolts(volts)
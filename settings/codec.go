@@ -0,0 +1,144 @@
+package settings
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"sort"
+	"strings"
+)
+
+/*
+Blob layout (little endian):
+
+	0  magic    "EPGS"
+	4  version  formatVersion
+	5  reserved 0
+	6  length   uint32, payload length in bytes
+	10 crc      uint32, CRC32 (IEEE) of the payload
+	14 payload  "key=value\n" lines sorted by key
+
+Newlines and backslashes in values are escaped as \n and \\. Keys may not
+contain '=' or newlines, Store.Set rejects them with ErrKey.
+*/
+
+const (
+	magic         = "EPGS"
+	formatVersion = 1
+	headerSize    = 14
+)
+
+var (
+	// ErrCorrupt means the stored data failed the magic, length or CRC check.
+	ErrCorrupt = errors.New("settings: stored data is corrupt")
+	// ErrVersion means the stored data was written by an unknown format version.
+	ErrVersion = errors.New("settings: unsupported format version")
+	// ErrKey means a key contains '=' or a newline, so can't be stored.
+	ErrKey = errors.New("settings: invalid key")
+)
+
+func encode(values map[string]string) []byte {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var payload strings.Builder
+	for _, k := range keys {
+		payload.WriteString(k)
+		payload.WriteByte('=')
+		payload.WriteString(escape(values[k]))
+		payload.WriteByte('\n')
+	}
+	body := []byte(payload.String())
+
+	data := make([]byte, headerSize+len(body))
+	copy(data, magic)
+	data[4] = formatVersion
+	binary.LittleEndian.PutUint32(data[6:], uint32(len(body)))
+	binary.LittleEndian.PutUint32(data[10:], crc32.ChecksumIEEE(body))
+	copy(data[headerSize:], body)
+	return data
+}
+
+// decode parses a blob written by encode. Empty or erased (all 0xFF) data
+// decodes to no settings rather than an error.
+func decode(data []byte) (map[string]string, error) {
+	values := map[string]string{}
+	if len(data) == 0 || isErased(data) {
+		return values, nil
+	}
+	if len(data) < headerSize || string(data[:4]) != magic {
+		return values, ErrCorrupt
+	}
+	if data[4] != formatVersion {
+		return values, ErrVersion
+	}
+	length := binary.LittleEndian.Uint32(data[6:])
+	if uint64(length) > uint64(len(data)-headerSize) {
+		return values, ErrCorrupt
+	}
+	body := data[headerSize : headerSize+int(length)]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(data[10:]) {
+		return values, ErrCorrupt
+	}
+	for _, line := range strings.Split(string(body), "\n") {
+		if line == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return map[string]string{}, ErrCorrupt
+		}
+		values[k] = unescape(v)
+	}
+	return values, nil
+}
+
+// payloadSize returns the total blob size announced by a header, or 0 if the
+// header is not valid. Used by backends that must know how much to read.
+func payloadSize(header []byte) int {
+	if len(header) < headerSize || string(header[:4]) != magic {
+		return 0
+	}
+	return headerSize + int(binary.LittleEndian.Uint32(header[6:]))
+}
+
+func isErased(data []byte) bool {
+	for _, b := range data {
+		if b != 0xFF {
+			return false
+		}
+	}
+	return true
+}
+
+// validKey reports whether key can be written as a "key=value" line.
+func validKey(key string) bool {
+	return !strings.ContainsAny(key, "=\n")
+}
+
+func escape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
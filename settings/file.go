@@ -0,0 +1,36 @@
+//go:build !tinygo
+
+package settings
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// FileBackend stores the settings blob in a file on the host.
+type FileBackend struct {
+	Path string
+}
+
+func NewFileBackend(path string) *FileBackend {
+	return &FileBackend{Path: path}
+}
+
+func (f *FileBackend) Load() ([]byte, error) {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Store writes to a temporary file and renames it over the old one, so an
+// interrupted write never leaves a half-written settings file behind.
+func (f *FileBackend) Store(data []byte) error {
+	tmp := f.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.Path)
+}
@@ -0,0 +1,55 @@
+//go:build tinygo
+
+package settings
+
+import "machine"
+
+// FlashBackend stores the settings blob at the start of the RP2040 flash data
+// area (the flash after the firmware image, see machine.FlashDataStart).
+type FlashBackend struct{}
+
+func NewFlashBackend() *FlashBackend {
+	return &FlashBackend{}
+}
+
+func (f *FlashBackend) Load() ([]byte, error) {
+	header := make([]byte, headerSize)
+	if _, err := machine.Flash.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+	size := payloadSize(header)
+	if size == 0 {
+		// Erased flash or garbage, let decode work out which
+		return header, nil
+	}
+	if int64(size) > machine.Flash.Size() {
+		return nil, ErrCorrupt
+	}
+	data := make([]byte, size)
+	if _, err := machine.Flash.ReadAt(data, 0); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Store erases as many flash blocks as needed and writes data padded to the
+// flash write block size. Only called when settings have changed, to limit wear.
+func (f *FlashBackend) Store(data []byte) error {
+	eraseSize := machine.Flash.EraseBlockSize()
+	blocks := (int64(len(data)) + eraseSize - 1) / eraseSize
+	if err := machine.Flash.EraseBlocks(0, blocks); err != nil {
+		return err
+	}
+	writeSize := machine.Flash.WriteBlockSize()
+	padded := int64(len(data))
+	if rem := padded % writeSize; rem != 0 {
+		padded += writeSize - rem
+	}
+	buf := make([]byte, padded)
+	for i := range buf {
+		buf[i] = 0xFF
+	}
+	copy(buf, data)
+	_, err := machine.Flash.WriteAt(buf, 0)
+	return err
+}
@@ -0,0 +1,21 @@
+package settings
+
+// MemoryBackend keeps the settings blob in memory. Used by the mock and tests.
+type MemoryBackend struct {
+	Data   []byte // the stored blob, exposed so tests can corrupt it
+	Writes int    // number of Store calls, to check that unchanged settings are not rewritten
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{}
+}
+
+func (m *MemoryBackend) Load() ([]byte, error) {
+	return append([]byte(nil), m.Data...), nil
+}
+
+func (m *MemoryBackend) Store(data []byte) error {
+	m.Data = append([]byte(nil), data...)
+	m.Writes++
+	return nil
+}
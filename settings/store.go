@@ -0,0 +1,179 @@
+// Package settings persists small key/value settings (app parameters,
+// calibration constants) across app exits and power cycles.
+//
+// A Store keeps the values in memory and writes them to a Backend (RP2040 flash
+// on hardware, a file or memory on the host) when Save is called and something
+// has changed. The stored blob carries a format version and a CRC so a
+// half-written or foreign flash area is detected instead of being trusted.
+package settings
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// IStore is the settings interface apps use, via Controls.Settings.
+// Keys are namespaced by convention, e.g. "pulsesync.mult1".
+type IStore interface {
+	Get(key string) (string, bool)
+	// Set stores value under key, returning ErrKey if the key can't be stored.
+	Set(key, value string) error
+	Delete(key string)
+	Keys() []string
+
+	GetInt(key string, def int) int
+	SetInt(key string, value int) error
+	GetFloat(key string, def float64) float64
+	SetFloat(key string, value float64) error
+	GetBool(key string, def bool) bool
+	SetBool(key string, value bool) error
+
+	// Save writes the settings to the backend if anything changed since the last load/save.
+	Save() error
+}
+
+// Backend stores the encoded settings blob.
+type Backend interface {
+	// Load returns the stored blob, or nil if nothing has been stored yet.
+	Load() ([]byte, error)
+	Store(data []byte) error
+}
+
+// Store implements IStore on top of a Backend.
+type Store struct {
+	mu      sync.Mutex
+	backend Backend
+	values  map[string]string
+	dirty   bool
+	// LoadErr records why the stored settings were discarded (ErrCorrupt,
+	// ErrVersion or a backend error), nil if they loaded cleanly.
+	LoadErr error
+}
+
+// New creates a Store and loads any existing settings from backend. If the stored
+// data is corrupt or from an unknown format version the store starts empty and
+// LoadErr says why.
+func New(backend Backend) *Store {
+	s := &Store{backend: backend, values: map[string]string{}}
+	s.LoadErr = s.Load()
+	return s
+}
+
+// Load replaces the in-memory values with the ones from the backend.
+// On error the store is left empty.
+func (s *Store) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = map[string]string{}
+	s.dirty = false
+	data, err := s.backend.Load()
+	if err != nil {
+		return err
+	}
+	values, err := decode(data)
+	if err != nil {
+		return err
+	}
+	s.values = values
+	return nil
+}
+
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	if err := s.backend.Store(encode(s.values)); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+func (s *Store) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Set stores value under key. Keys containing '=' or a newline are rejected with ErrKey rather than written,
+// as they'd make the whole blob fail to load.
+func (s *Store) Set(key, value string) error {
+	if !validKey(key) {
+		return ErrKey
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.values[key]; ok && old == value {
+		return nil
+	}
+	s.values[key] = value
+	s.dirty = true
+	return nil
+}
+
+func (s *Store) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.dirty = true
+	}
+}
+
+// Keys returns all keys in sorted order.
+func (s *Store) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetInt returns the value for key as an int, or def if missing or unparsable.
+func (s *Store) GetInt(key string, def int) int {
+	if v, ok := s.Get(key); ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return def
+}
+
+func (s *Store) SetInt(key string, value int) error {
+	return s.Set(key, strconv.Itoa(value))
+}
+
+// GetFloat returns the value for key as a float64, or def if missing or unparsable.
+func (s *Store) GetFloat(key string, def float64) float64 {
+	if v, ok := s.Get(key); ok {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
+
+func (s *Store) SetFloat(key string, value float64) error {
+	return s.Set(key, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// GetBool returns the value for key as a bool, or def if missing or unparsable.
+func (s *Store) GetBool(key string, def bool) bool {
+	if v, ok := s.Get(key); ok {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+func (s *Store) SetBool(key string, value bool) error {
+	return s.Set(key, strconv.FormatBool(value))
+}
@@ -0,0 +1,142 @@
+// Settings store tests
+package settings
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	backend := NewMemoryBackend()
+	s := New(backend)
+	if s.LoadErr != nil {
+		t.Fatalf("Expected empty backend to load cleanly, got %v", s.LoadErr)
+	}
+	s.SetInt("pulsesync.mult1", 4)
+	s.SetFloat("pulsesync.mult4", 2.5)
+	s.SetBool("pulsesync.sync", false)
+	s.Set("name", "multi\nline \\ value")
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New(backend)
+	if loaded.LoadErr != nil {
+		t.Fatalf("Expected saved settings to load cleanly, got %v", loaded.LoadErr)
+	}
+	if v := loaded.GetInt("pulsesync.mult1", 0); v != 4 {
+		t.Errorf("Expected 4, got %d", v)
+	}
+	if v := loaded.GetFloat("pulsesync.mult4", 0); v != 2.5 {
+		t.Errorf("Expected 2.5, got %v", v)
+	}
+	if v := loaded.GetBool("pulsesync.sync", true); v != false {
+		t.Errorf("Expected false, got %v", v)
+	}
+	if v, _ := loaded.Get("name"); v != "multi\nline \\ value" {
+		t.Errorf("Expected escaped value to round trip, got %q", v)
+	}
+	if v := loaded.GetInt("missing", 7); v != 7 {
+		t.Errorf("Expected default 7 for missing key, got %d", v)
+	}
+	if keys := loaded.Keys(); len(keys) != 4 || keys[0] != "name" {
+		t.Errorf("Expected 4 sorted keys, got %v", keys)
+	}
+}
+
+func TestStoreOnlyWritesWhenChanged(t *testing.T) {
+	backend := NewMemoryBackend()
+	s := New(backend)
+	s.SetInt("a", 1)
+	s.Save()
+	s.Save()
+	s.SetInt("a", 1) // same value, not dirty
+	s.Save()
+	if backend.Writes != 1 {
+		t.Errorf("Expected 1 write, got %d", backend.Writes)
+	}
+	s.Delete("a")
+	s.Save()
+	if backend.Writes != 2 {
+		t.Errorf("Expected delete to cause a write, got %d writes", backend.Writes)
+	}
+}
+
+func TestStoreRejectsBadKeys(t *testing.T) {
+	backend := NewMemoryBackend()
+	s := New(backend)
+	s.SetInt("pulsesync.mult1", 4)
+	for _, key := range []string{"a=b", "two\nlines"} {
+		if err := s.Set(key, "x"); err != ErrKey {
+			t.Errorf("Expected ErrKey for %q, got %v", key, err)
+		}
+	}
+	if err := s.SetInt("tgd=width", 25); err != ErrKey {
+		t.Errorf("Expected SetInt to reject a bad key too, got %v", err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The other settings survive the round trip
+	loaded := New(backend)
+	if loaded.LoadErr != nil {
+		t.Fatalf("Expected settings to load cleanly, got %v", loaded.LoadErr)
+	}
+	if keys := loaded.Keys(); len(keys) != 1 || loaded.GetInt("pulsesync.mult1", 0) != 4 {
+		t.Errorf("Expected only pulsesync.mult1 to be stored, got %v", keys)
+	}
+}
+
+func TestStoreDetectsCorruption(t *testing.T) {
+	backend := NewMemoryBackend()
+	s := New(backend)
+	s.SetInt("a", 1)
+	s.Save()
+
+	backend.Data[len(backend.Data)-2] ^= 0x01 // flip a payload bit
+	corrupt := New(backend)
+	if corrupt.LoadErr != ErrCorrupt {
+		t.Errorf("Expected ErrCorrupt, got %v", corrupt.LoadErr)
+	}
+	if len(corrupt.Keys()) != 0 {
+		t.Errorf("Expected corrupt settings to be discarded, got %v", corrupt.Keys())
+	}
+
+	backend.Data = backend.Data[:headerSize+1] // truncated write
+	if New(backend).LoadErr != ErrCorrupt {
+		t.Error("Expected truncated data to be reported as corrupt")
+	}
+}
+
+func TestStoreVersionAndErasedFlash(t *testing.T) {
+	backend := NewMemoryBackend()
+	s := New(backend)
+	s.SetInt("a", 1)
+	s.Save()
+	backend.Data[4] = formatVersion + 1
+	if err := New(backend).LoadErr; err != ErrVersion {
+		t.Errorf("Expected ErrVersion, got %v", err)
+	}
+
+	// Freshly erased flash reads back as 0xFF and means "no settings yet"
+	backend.Data = []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	if err := New(backend).LoadErr; err != nil {
+		t.Errorf("Expected erased flash to load as empty, got %v", err)
+	}
+}
+
+func TestFileBackend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.dat")
+	s := New(NewFileBackend(path))
+	if s.LoadErr != nil {
+		t.Fatalf("Expected missing file to load as empty, got %v", s.LoadErr)
+	}
+	s.SetInt("tgd.width", 25)
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if v := New(NewFileBackend(path)).GetInt("tgd.width", 0); v != 25 {
+		t.Errorf("Expected 25 from file, got %d", v)
+	}
+}
@@ -1,6 +1,9 @@
 package util
 
-import "time"
+import (
+	"europi/clock"
+	"time"
+)
 
 // SmartKnobProcessor with smart locking and filtering
 //   - SmartKnobProcessor processes raw knob values, applies filtering, and manages locking behavior
@@ -16,9 +19,12 @@ type SmartKnobProcessor struct {
 	LockAfter        time.Duration // idle time to lock
 	ResumeThreshold  int
 	LastActivityTime time.Time
+	Clock            clock.Clock
+	Resolution       int // output range is 0..Resolution
+	RawMin, RawMax   int // raw ADC readings at the ends of the knob's travel
 }
 
-func NewSmartKnobProcessor() *SmartKnobProcessor {
+func NewSmartKnobProcessor(clk clock.Clock) *SmartKnobProcessor {
 	return &SmartKnobProcessor{
 		Filter:           NewAnalogFilter(8), // To smooth more, increase window size
 		LastMapped:       -1,
@@ -26,14 +32,18 @@ func NewSmartKnobProcessor() *SmartKnobProcessor {
 		IsLocked:         false,
 		LockAfter:        500 * time.Millisecond,
 		ResumeThreshold:  2,
-		LastActivityTime: time.Now(),
+		LastActivityTime: clk.Now(),
+		Clock:            clk,
+		Resolution:       100,
+		RawMin:           0,
+		RawMax:           65535,
 	}
 }
 
 func (k *SmartKnobProcessor) Process(rawValue int) int {
 	filtered := k.Filter.Update(rawValue)
-	mapped := 100 - CalibrateKnobValue(filtered, 0, 65535, 0, 100) // maps to 0..100
-	now := time.Now()
+	mapped := k.Resolution - CalibrateKnobValue(filtered, k.RawMin, k.RawMax, 0, k.Resolution) // maps to 0..Resolution
+	now := k.Clock.Now()
 
 	if k.LastMapped == -1 {
 		k.LastMapped = mapped
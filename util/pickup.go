@@ -0,0 +1,94 @@
+package util
+
+// PickupMode selects how a Pickup takes over a parameter from a knob.
+type PickupMode int
+
+const (
+	// PickupCatch ignores the knob until it reaches or passes through the
+	// parameter's stored value, then follows it directly.
+	PickupCatch PickupMode = iota
+	// PickupScaled changes the value straight away, but scaled so that value
+	// and knob meet at the end of the travel the knob is moving towards.
+	PickupScaled
+)
+
+// Pickup implements soft takeover ("pickup") for a knob shared between several
+// parameters, e.g. one knob editing whichever channel is selected. Without it,
+// touching the knob after switching channels makes the new channel jump to the
+// knob position.
+//
+// Values are in knob units (Min..Max, 0..100 by default). Call Reset whenever
+// the knob is handed a different parameter, then Update with every knob reading.
+type Pickup struct {
+	Mode      PickupMode
+	Min, Max  int
+	Threshold int // the knob counts as caught when within Threshold of the value
+
+	value    float64
+	lastKnob int
+	caught   bool
+}
+
+func NewPickup(mode PickupMode) *Pickup {
+	return &Pickup{Mode: mode, Min: 0, Max: 100, Threshold: 1}
+}
+
+// Reset hands the knob a new parameter whose current value (in knob units) is
+// value, with the knob currently at knob.
+func (p *Pickup) Reset(value, knob int) {
+	p.value = float64(Clamp(value, p.Min, p.Max))
+	p.lastKnob = knob
+	p.caught = Abs(knob-value) <= p.Threshold
+}
+
+// Update feeds the latest knob reading and returns the parameter value and
+// whether it changed.
+func (p *Pickup) Update(knob int) (int, bool) {
+	if knob == p.lastKnob {
+		return p.Value(), false
+	}
+	old := p.Value()
+	last := p.lastKnob
+	p.lastKnob = knob
+
+	if !p.caught {
+		crossed := (last-old)*(knob-old) <= 0 // knob is on, or has moved across, the value
+		if crossed || Abs(knob-old) <= p.Threshold {
+			p.caught = true
+		} else if p.Mode == PickupScaled {
+			p.scale(last, knob)
+			if Abs(knob-p.Value()) <= p.Threshold {
+				p.caught = true
+			}
+			return p.Value(), p.Value() != old
+		} else {
+			return old, false
+		}
+	}
+	p.value = float64(knob)
+	return p.Value(), p.Value() != old
+}
+
+// scale moves the value by the knob's movement as a fraction of the remaining
+// travel, so value and knob arrive at Min or Max together.
+func (p *Pickup) scale(last, knob int) {
+	if knob > last {
+		if remaining := p.Max - last; remaining > 0 {
+			p.value += float64(knob-last) * (float64(p.Max) - p.value) / float64(remaining)
+		}
+	} else {
+		if remaining := last - p.Min; remaining > 0 {
+			p.value -= float64(last-knob) * (p.value - float64(p.Min)) / float64(remaining)
+		}
+	}
+}
+
+// Value returns the parameter value in knob units.
+func (p *Pickup) Value() int {
+	return int(p.value + 0.5)
+}
+
+// Caught reports whether the knob is now directly controlling the value.
+func (p *Pickup) Caught() bool {
+	return p.caught
+}
@@ -0,0 +1,60 @@
+package util
+
+import "testing"
+
+func TestPickupCatch(t *testing.T) {
+	p := NewPickup(PickupCatch)
+	p.Reset(60, 20) // stored value 60, knob sitting at 20
+
+	for _, knob := range []int{25, 40, 55} {
+		if v, changed := p.Update(knob); changed || v != 60 {
+			t.Fatalf("Knob at %d: expected value to stay 60, got %d (changed=%v)", knob, v, changed)
+		}
+	}
+	if p.Caught() {
+		t.Fatal("Expected knob not to be caught before reaching the value")
+	}
+	// Knob jumps across the stored value, as happens with coarse readings
+	if v, changed := p.Update(62); !changed || v != 62 {
+		t.Errorf("Expected knob to take over at 62, got %d (changed=%v)", v, changed)
+	}
+	if v, _ := p.Update(30); v != 30 {
+		t.Errorf("Expected caught knob to follow directly, got %d", v)
+	}
+}
+
+func TestPickupCatchFromAbove(t *testing.T) {
+	p := NewPickup(PickupCatch)
+	p.Reset(10, 90)
+	if v, _ := p.Update(50); v != 10 {
+		t.Errorf("Expected value to stay 10, got %d", v)
+	}
+	if v, _ := p.Update(11); v != 11 || !p.Caught() {
+		t.Errorf("Expected knob within threshold to be caught, got %d caught=%v", v, p.Caught())
+	}
+}
+
+func TestPickupAlreadyAligned(t *testing.T) {
+	p := NewPickup(PickupCatch)
+	p.Reset(50, 50)
+	if v, changed := p.Update(52); !changed || v != 52 {
+		t.Errorf("Expected aligned knob to follow immediately, got %d (changed=%v)", v, changed)
+	}
+}
+
+func TestPickupScaled(t *testing.T) {
+	p := NewPickup(PickupScaled)
+	p.Reset(80, 0) // knob at the bottom, value near the top
+
+	v, changed := p.Update(50)
+	if !changed || v != 90 {
+		// half the remaining knob travel moves the value half its remaining travel
+		t.Errorf("Expected scaled value 90, got %d (changed=%v)", v, changed)
+	}
+	if v, _ := p.Update(100); v != 100 || !p.Caught() {
+		t.Errorf("Expected value and knob to meet at 100, got %d caught=%v", v, p.Caught())
+	}
+	if v, _ := p.Update(70); v != 70 {
+		t.Errorf("Expected caught knob to follow directly, got %d", v)
+	}
+}
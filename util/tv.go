@@ -1,6 +1,7 @@
 package util
 
 import (
+	"europi/clock"
 	"sync"
 	"time"
 )
@@ -16,11 +17,17 @@ type TimeVisualiser struct {
 	maxChars       int
 	interval       time.Duration
 	lastAdvance    time.Time
+	clock          clock.Clock
 	displayUpdates chan string // channel for sending display updates, create a goroutine to read from this
 }
 
 // NewTimeVisualiser creates a new TimeVisualiser with fixed-size rune buffers.
 func NewTimeVisualiser(numLines, maxChars int, interval time.Duration) *TimeVisualiser {
+	return NewTimeVisualiserWithClock(numLines, maxChars, interval, clock.Real{})
+}
+
+// NewTimeVisualiserWithClock is NewTimeVisualiser advancing on clk, e.g. hw.Clock
+func NewTimeVisualiserWithClock(numLines, maxChars int, interval time.Duration, clk clock.Clock) *TimeVisualiser {
 	if numLines < 1 {
 		numLines = 1
 	}
@@ -40,7 +47,8 @@ func NewTimeVisualiser(numLines, maxChars int, interval time.Duration) *TimeVisu
 		numLines:       numLines,
 		maxChars:       maxChars,
 		interval:       interval,
-		lastAdvance:    time.Now(),
+		lastAdvance:    clk.Now(),
+		clock:          clk,
 		displayUpdates: make(chan string, 8),
 		lastDisplayBuf: make([]rune, bufSize),
 		displayBuf:     make([]rune, bufSize),		
@@ -55,7 +63,7 @@ func (tv *TimeVisualiser) AddChar(line int, c rune) {
 		return
 	}
 
-	now := time.Now()
+	now := tv.clock.Now()
 	for now.Sub(tv.lastAdvance) >= tv.interval {
 		nextAdvance := tv.lastAdvance.Add(tv.interval)
 		tv.advanceLocked(nextAdvance)
@@ -87,7 +95,7 @@ func (tv *TimeVisualiser) Clear() {
 		}
 	}
 	tv.cursor = 0
-	tv.lastAdvance = time.Now()
+	tv.lastAdvance = tv.clock.Now()
 	tv.notifyLocked()
 }
 
@@ -0,0 +1,18 @@
+package util
+
+import (
+	"europi/clock"
+	"testing"
+	"time"
+)
+
+func TestTimeVisualiserAdvancesWithClock(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	tv := NewTimeVisualiserWithClock(1, 10, 100*time.Millisecond, clk)
+	tv.AddChar(0, 'a')
+	clk.Advance(200 * time.Millisecond)
+	tv.AddChar(0, 'b')
+	if got := tv.Display(); got != "a b" {
+		t.Errorf("Expected the cursor to move 2 places in 200ms, got %q", got)
+	}
+}
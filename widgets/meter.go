@@ -0,0 +1,71 @@
+package widgets
+
+import (
+	"europi/graphics"
+)
+
+// Bar is a horizontal meter showing Value between Min and Max. When the range
+// spans zero, e.g. -5 to 5 volts, it fills out from zero instead of the left.
+type Bar struct {
+	Value    float64
+	Min, Max float64
+}
+
+// fraction returns where v sits between min and max, 0 to 1.
+func fraction(v, lo, hi float64) float64 {
+	if hi <= lo {
+		return 0
+	}
+	return max(0, min(1, (v-lo)/(hi-lo)))
+}
+
+func (b Bar) Draw(c *graphics.Canvas, a Area) {
+	if a.W < 3 || a.H < 3 {
+		return
+	}
+	c.Rect(a.X, a.Y, a.W, a.H)
+	inner := a.Inset(1)
+	toX := func(v float64) int16 {
+		return inner.X + int16(fraction(v, b.Min, b.Max)*float64(inner.W)+0.5)
+	}
+	from := inner.X
+	if b.Min < 0 && b.Max > 0 {
+		from = toX(0)
+		c.VLine(from, a.Y, a.H) // zero mark
+	}
+	to := toX(b.Value)
+	if to < from {
+		from, to = to, from
+	}
+	c.FillRect(from, inner.Y, to-from, inner.H)
+}
+
+// KnobArc draws a knob: an arc sweeping from 7 o'clock round to the
+// position of Value between Min and Max, with a pointer at the end.
+type KnobArc struct {
+	Value    float64
+	Min, Max float64
+}
+
+// The sweep of a knob, in degrees clockwise from 12 o'clock.
+const (
+	knobStart = -135.0
+	knobSweep = 270.0
+)
+
+func (k KnobArc) Draw(c *graphics.Canvas, a Area) {
+	r := min(a.W, a.H)/2 - 1
+	if r < 2 {
+		return
+	}
+	cx, cy := a.X+a.W/2, a.Y+a.H/2
+	end := knobStart + knobSweep*fraction(k.Value, k.Min, k.Max)
+	// Tick marks at both ends of the travel, so a knob at zero still shows its range
+	for _, angle := range []float64{knobStart, knobStart + knobSweep} {
+		x, y := graphics.PointOnCircle(cx, cy, r, angle)
+		c.Pixel(x, y)
+	}
+	c.Arc(cx, cy, r, knobStart, end)
+	x, y := graphics.PointOnCircle(cx, cy, r-2, end)
+	c.Line(cx, cy, x, y)
+}
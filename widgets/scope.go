@@ -0,0 +1,104 @@
+package widgets
+
+import (
+	"europi/clock"
+	"europi/graphics"
+	"time"
+)
+
+// Scope is a little oscilloscope trace of the last samples added, oldest on
+// the left, scaled so Min is the bottom of its area and Max the top.
+type Scope struct {
+	Min, Max float64
+	samples  []float64
+	next     int
+	full     bool
+}
+
+// NewScope returns a scope remembering the last n samples, usually the width it's drawn at.
+func NewScope(n int, min, max float64) *Scope {
+	return &Scope{Min: min, Max: max, samples: make([]float64, n)}
+}
+
+// Add records a sample, dropping the oldest once the scope is full.
+func (s *Scope) Add(v float64) {
+	if len(s.samples) == 0 {
+		return
+	}
+	s.samples[s.next] = v
+	s.next = (s.next + 1) % len(s.samples)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+// Samples returns the samples in the order they were added.
+func (s *Scope) Samples() []float64 {
+	if !s.full {
+		return append([]float64(nil), s.samples[:s.next]...)
+	}
+	return append(append([]float64(nil), s.samples[s.next:]...), s.samples[:s.next]...)
+}
+
+func (s *Scope) Draw(c *graphics.Canvas, a Area) {
+	samples := s.Samples()
+	if len(samples) == 0 || a.W < 1 || a.H < 1 {
+		return
+	}
+	toY := func(v float64) int16 {
+		return a.Y + a.H - 1 - int16(fraction(v, s.Min, s.Max)*float64(a.H-1)+0.5)
+	}
+	// Spread the samples across the width, joining them up with lines
+	var lastX, lastY int16
+	for i, v := range samples {
+		x := a.X
+		if len(samples) > 1 {
+			x += int16(i * int(a.W-1) / (len(samples) - 1))
+		}
+		y := toY(v)
+		if i == 0 {
+			c.Pixel(x, y)
+		} else {
+			c.Line(lastX, lastY, x, y)
+		}
+		lastX, lastY = x, y
+	}
+}
+
+// TempoBlinker is a dot that lights up for Flash after each Beat, like a tempo LED.
+type TempoBlinker struct {
+	Clock clock.Clock
+	Flash time.Duration
+	last  time.Time
+}
+
+// NewTempoBlinker returns a blinker that stays lit for 50ms after each beat.
+func NewTempoBlinker(clk clock.Clock) *TempoBlinker {
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	return &TempoBlinker{Clock: clk, Flash: 50 * time.Millisecond}
+}
+
+// Beat lights the blinker.
+func (b *TempoBlinker) Beat() {
+	b.last = b.Clock.Now()
+}
+
+// Lit reports whether the blinker is showing a beat.
+func (b *TempoBlinker) Lit() bool {
+	return !b.last.IsZero() && b.Clock.Now().Sub(b.last) < b.Flash
+}
+
+func (b *TempoBlinker) Draw(c *graphics.Canvas, a Area) {
+	r := min(a.W, a.H)/2 - 1
+	if r < 1 {
+		return
+	}
+	cx, cy := a.X+a.W/2, a.Y+a.H/2
+	if b.Lit() {
+		c.FillCircle(cx, cy, r)
+	} else {
+		c.Circle(cx, cy, r)
+	}
+}
@@ -0,0 +1,92 @@
+package widgets
+
+import (
+	"europi/graphics"
+)
+
+// Align is where text sits across its area.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCentre
+	AlignRight
+)
+
+// fit truncates text to the characters that fit in width pixels.
+func fit(text string, width int16) string {
+	n := int(width / graphics.CharWidth)
+	if n < 0 {
+		n = 0
+	}
+	if len(text) > n {
+		return text[:n]
+	}
+	return text
+}
+
+// textY centres a line of text vertically in the area.
+func textY(a Area) int16 {
+	return a.Y + (a.H-graphics.CharHeight)/2
+}
+
+func drawText(c *graphics.Canvas, a Area, text string, align Align) {
+	text = fit(text, a.W)
+	x := a.X
+	switch align {
+	case AlignCentre:
+		x += (a.W - graphics.TextWidth(text)) / 2
+	case AlignRight:
+		x += a.W - graphics.TextWidth(text)
+	}
+	c.Text(x, textY(a), text)
+}
+
+// Label is a line of text.
+type Label struct {
+	Text  string
+	Align Align
+}
+
+func (l Label) Draw(c *graphics.Canvas, a Area) {
+	drawText(c, a, l.Text, l.Align)
+}
+
+// Value is a label on the left with its value on the right, e.g. "BPM   120".
+// If both don't fit, the label is cut short so the value stays readable.
+// Selected draws the value inverted, for the parameter being edited.
+type Value struct {
+	Label    string
+	Value    string
+	Selected bool
+}
+
+func (v Value) Draw(c *graphics.Canvas, a Area) {
+	value := fit(v.Value, a.W)
+	valueW := graphics.TextWidth(value)
+	label := fit(v.Label, a.W-valueW)
+	c.Text(a.X, textY(a), label)
+	x := a.X + a.W - valueW
+	if v.Selected {
+		c.TextInverse(x, textY(a), value)
+	} else {
+		c.Text(x, textY(a), value)
+	}
+}
+
+// Toggle is a checkbox followed by a label, ticked when On.
+type Toggle struct {
+	Label string
+	On    bool
+}
+
+func (t Toggle) Draw(c *graphics.Canvas, a Area) {
+	size := min(a.H, int16(8))
+	y := a.Y + (a.H-size)/2
+	c.Rect(a.X, y, size, size)
+	if t.On && size > 4 {
+		c.FillRect(a.X+2, y+2, size-4, size-4)
+	}
+	_, rest := a.SplitLeft(size + 2)
+	drawText(c, rest, t.Label, AlignLeft)
+}
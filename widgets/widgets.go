@@ -0,0 +1,119 @@
+// Package widgets draws common bits of an app screen, like labelled values,
+// meters and scope traces, so screens look alike across apps. Widgets draw
+// with the graphics package into an area of the screen, which the layout
+// helpers here carve up:
+//
+//	screen, ok := widgets.NewScreen(hw.Display)
+//	rows := screen.Area().Rows(3)
+//	screen.Clear()
+//	screen.Draw(widgets.Value{Label: "BPM", Value: "120"}, rows[0])
+//	screen.Draw(widgets.Bar{Value: cv, Max: 10}, rows[1])
+//	screen.Show()
+package widgets
+
+import (
+	"europi/display"
+	"europi/graphics"
+)
+
+// Widget is anything that can draw itself into an area of the screen.
+type Widget interface {
+	Draw(c *graphics.Canvas, area Area)
+}
+
+// Screen draws widgets onto the OLED, or the framebuffer of a mock display.
+type Screen struct {
+	canvas *graphics.Canvas
+}
+
+// NewScreen returns a screen for oled, or false if it has no pixel device.
+func NewScreen(oled display.IOledDevice) (*Screen, bool) {
+	dev, ok := oled.GetSSD1306().(display.ISSD1306Device)
+	if !ok {
+		return nil, false
+	}
+	return NewScreenOn(dev), true
+}
+
+// NewScreenOn returns a screen drawing straight onto dev, e.g. a display.Framebuffer.
+func NewScreenOn(dev display.ISSD1306Device) *Screen {
+	return &Screen{canvas: graphics.New(dev)}
+}
+
+// Canvas returns the canvas for drawing anything the widgets don't cover.
+func (s *Screen) Canvas() *graphics.Canvas {
+	return s.canvas
+}
+
+// Area returns the whole screen.
+func (s *Screen) Area() Area {
+	w, h := s.canvas.Size()
+	return Area{0, 0, w, h}
+}
+
+// Clear blanks the buffer, ready for the next frame.
+func (s *Screen) Clear() {
+	s.canvas.Device().ClearBuffer()
+}
+
+// Draw draws w into area.
+func (s *Screen) Draw(w Widget, area Area) {
+	w.Draw(s.canvas, area)
+}
+
+// Show sends the frame to the display.
+func (s *Screen) Show() {
+	s.canvas.Device().Display()
+}
+
+// Area is a part of the screen, with helpers to split it up for layout.
+type Area graphics.Rect
+
+// Rows splits the area into n rows of equal height, any spare pixels going to the last.
+func (a Area) Rows(n int) []Area {
+	if n < 1 {
+		return nil
+	}
+	rows := make([]Area, n)
+	h := a.H / int16(n)
+	for i := range rows {
+		rows[i] = Area{a.X, a.Y + int16(i)*h, a.W, h}
+	}
+	rows[n-1].H = a.Y + a.H - rows[n-1].Y
+	return rows
+}
+
+// Columns splits the area into n columns of equal width, any spare pixels going to the last.
+func (a Area) Columns(n int) []Area {
+	if n < 1 {
+		return nil
+	}
+	cols := make([]Area, n)
+	w := a.W / int16(n)
+	for i := range cols {
+		cols[i] = Area{a.X + int16(i)*w, a.Y, w, a.H}
+	}
+	cols[n-1].W = a.X + a.W - cols[n-1].X
+	return cols
+}
+
+// SplitLeft cuts the area in two, the left part being width pixels wide.
+func (a Area) SplitLeft(width int16) (left, right Area) {
+	width = max(0, min(width, a.W))
+	return Area{a.X, a.Y, width, a.H}, Area{a.X + width, a.Y, a.W - width, a.H}
+}
+
+// SplitTop cuts the area in two, the top part being height pixels high.
+func (a Area) SplitTop(height int16) (top, bottom Area) {
+	height = max(0, min(height, a.H))
+	return Area{a.X, a.Y, a.W, height}, Area{a.X, a.Y + height, a.W, a.H - height}
+}
+
+// Inset shrinks the area by margin pixels on every side.
+func (a Area) Inset(margin int16) Area {
+	a.X += margin
+	a.Y += margin
+	a.W = max(0, a.W-2*margin)
+	a.H = max(0, a.H-2*margin)
+	return a
+}
@@ -0,0 +1,112 @@
+package widgets
+
+import (
+	"europi/clock"
+	"europi/display"
+	"europi/display/displaytest"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLayout(t *testing.T) {
+	screen := NewScreenOn(display.NewFramebuffer(128, 32))
+	rows := screen.Area().Rows(3)
+	if len(rows) != 3 || rows[0] != (Area{0, 0, 128, 10}) || rows[2] != (Area{0, 20, 128, 12}) {
+		t.Errorf("Unexpected rows %+v", rows)
+	}
+	cols := rows[1].Columns(3)
+	if cols[0] != (Area{0, 10, 42, 10}) || cols[2] != (Area{84, 10, 44, 10}) {
+		t.Errorf("Unexpected columns %+v", cols)
+	}
+	left, right := screen.Area().SplitLeft(100)
+	if left.W != 100 || right != (Area{100, 0, 28, 32}) {
+		t.Errorf("Unexpected split %+v %+v", left, right)
+	}
+	top, bottom := screen.Area().SplitTop(40)
+	if top.H != 32 || bottom.H != 0 {
+		t.Errorf("Expected an oversized split to be clamped, got %+v %+v", top, bottom)
+	}
+	if in := right.Inset(2); in != (Area{102, 2, 24, 28}) {
+		t.Errorf("Unexpected inset %+v", in)
+	}
+}
+
+func TestBar(t *testing.T) {
+	fb := display.NewFramebuffer(12, 3)
+	screen := NewScreenOn(fb)
+	screen.Draw(Bar{Value: 5, Max: 10}, screen.Area())
+	want := "" +
+		"############\n" +
+		"######.....#\n" +
+		"############\n"
+	if got := fb.String(); got != want {
+		t.Errorf("Expected a half full bar:\n%s\nGot:\n%s", want, got)
+	}
+
+	// A bipolar bar fills from the zero mark
+	fb.ClearBuffer()
+	screen.Draw(Bar{Value: -2.5, Min: -5, Max: 5}, screen.Area())
+	want = "" +
+		"############\n" +
+		"#...###....#\n" +
+		"############\n"
+	if got := fb.String(); got != want {
+		t.Errorf("Expected a bar filled left of zero:\n%s\nGot:\n%s", want, got)
+	}
+}
+
+func TestScopeAndBlinker(t *testing.T) {
+	scope := NewScope(3, 0, 10)
+	for _, v := range []float64{1, 2, 3, 4} {
+		scope.Add(v)
+	}
+	if got := scope.Samples(); len(got) != 3 || got[0] != 2 || got[2] != 4 {
+		t.Errorf("Expected the oldest sample to be dropped, got %v", got)
+	}
+
+	clk := clock.NewVirtual(time.Time{})
+	blink := NewTempoBlinker(clk)
+	if blink.Lit() {
+		t.Error("Expected the blinker to start dark")
+	}
+	blink.Beat()
+	clk.Advance(40 * time.Millisecond)
+	if !blink.Lit() {
+		t.Error("Expected the blinker to be lit just after a beat")
+	}
+	clk.Advance(20 * time.Millisecond)
+	if blink.Lit() {
+		t.Error("Expected the blinker to go dark after Flash")
+	}
+}
+
+func TestWidgetScreenGolden(t *testing.T) {
+	oled := display.NewMockOledDevice(3, 16)
+	screen, ok := NewScreen(oled)
+	if !ok {
+		t.Fatal("Expected the mock display to support widgets")
+	}
+	clk := clock.NewVirtual(time.Time{})
+	blink := NewTempoBlinker(clk)
+	blink.Beat()
+	scope := NewScope(32, -1, 1)
+	for i := 0; i < 32; i++ {
+		scope.Add(math.Sin(float64(i) * 2 * math.Pi / 32))
+	}
+
+	screen.Clear()
+	rows := screen.Area().Rows(3)
+	left, knobArea := rows[0].SplitLeft(112)
+	screen.Draw(Value{Label: "Tempo", Value: "120", Selected: true}, left)
+	screen.Draw(KnobArc{Value: 0.75, Max: 1}, knobArea)
+	cols := rows[1].Columns(2)
+	screen.Draw(Toggle{Label: "Sync", On: true}, cols[0])
+	screen.Draw(Bar{Value: 3, Min: -5, Max: 5}, cols[1].Inset(1))
+	scopeArea, blinkArea := rows[2].SplitLeft(112)
+	screen.Draw(scope, scopeArea)
+	screen.Draw(blink, blinkArea)
+	screen.Show()
+
+	displaytest.AssertGolden(t, oled.Framebuffer(), "widgets")
+}